@@ -0,0 +1,85 @@
+package entities
+
+import "time"
+
+// List of purposes one-time user tokens are issued for.
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+)
+
+// Principal represents authenticated subject.
+type Principal struct {
+	UserID      string
+	Roles       []string
+	Permissions []string
+}
+
+// HasRole reports whether principal was granted given role.
+func (p Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasPermission reports whether any of principal roles grants given permission.
+func (p Principal) HasPermission(permission string) bool {
+	for _, perm := range p.Permissions {
+		if perm == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CanView reports whether principal is allowed to read profile of given user:
+// users may read their own profile, staff listing or managing users any profile.
+func (p Principal) CanView(userID string) bool {
+	return p.UserID == userID || p.HasPermission(PermUsersList) || p.HasPermission(PermUsersManage)
+}
+
+// CanActOn reports whether principal is allowed to act on account of given user:
+// users may act on their own account, staff with PermUsersManage on any account.
+func (p Principal) CanActOn(userID string) bool {
+	return p.UserID == userID || p.HasPermission(PermUsersManage)
+}
+
+// TokenPair holds on tokens issued to authenticated user.
+type TokenPair struct {
+	AccessToken  string
+	ExpiresAt    time.Time
+	RefreshToken string
+}
+
+// RefreshToken represents stored refresh token.
+// Only hash of the token is kept in repository.
+type RefreshToken struct {
+	ID        string
+	UserID    string
+	TokenHash string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
+
+// UserToken represents stored one-time token
+// issued to the user for specific purpose.
+// Only hash of the token is kept in repository.
+type UserToken struct {
+	ID        string
+	UserID    string
+	Purpose   string
+	TokenHash string
+	ExpiresAt time.Time
+}
+
+// Notification is a message delivered to the user.
+type Notification struct {
+	To      string
+	Subject string
+	Body    string
+}
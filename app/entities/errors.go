@@ -0,0 +1,136 @@
+package entities
+
+import "strings"
+
+// Kind classifies domain errors, so transport can report them
+// without knowing every error of the catalogue.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindInvalid
+	KindUnauthorized
+	KindForbidden
+)
+
+// Error is domain error. Code and Message are safe to be shown to clients,
+// while Cause holds internal details which are meant for logs only.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Cause   error
+}
+
+func (e *Error) Error() string {
+	if e.Cause == nil {
+		return e.Message
+	}
+
+	return e.Message + ": " + e.Cause.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether target is the same catalogue error regardless of the cause,
+// so errors.Is(err, ErrNotFound) holds for wrapped copies too.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error) //nolint:errorlint // Target is compared as is, unwrapping is done by errors.Is.
+
+	return ok && t.Code == e.Code
+}
+
+// Wrap returns copy of catalogue error e caused by err.
+func (e *Error) Wrap(err error) error {
+	return &Error{Kind: e.Kind, Code: e.Code, Message: e.Message, Cause: err}
+}
+
+// Catalogue of domain errors. Returned errors are either these
+// or their copies made by Wrap, so they have to be compared with errors.Is.
+//
+//nolint:gochecknoglobals // Errors are never modified, they are copied by Wrap.
+var (
+	ErrNotFound           = &Error{Kind: KindNotFound, Code: "not_found", Message: "user not found"}
+	ErrRoleNotFound       = &Error{Kind: KindNotFound, Code: "role_not_found", Message: "role not found"}
+	ErrTokenNotFound      = &Error{Kind: KindNotFound, Code: "token_not_found", Message: "token not found or expired"}
+	ErrReferenceNotFound  = &Error{Kind: KindNotFound, Code: "reference_not_found", Message: "referenced resource does not exist"}
+	ErrDuplicateEmail     = &Error{Kind: KindConflict, Code: "email_taken", Message: "email is already taken"}
+	ErrConflict           = &Error{Kind: KindConflict, Code: "conflict", Message: "resource already exists"}
+	ErrConcurrentUpdate   = &Error{Kind: KindConflict, Code: "concurrent_update", Message: "resource was changed concurrently, retry the request"}
+	ErrConstraint         = &Error{Kind: KindInvalid, Code: "constraint_violated", Message: "data violates constraint"}
+	ErrMalformedCursor    = &Error{Kind: KindInvalid, Code: "malformed_cursor", Message: "cursor is malformed or does not match sort"}
+	ErrPasswordTooLong    = &Error{Kind: KindInvalid, Code: "password_too_long", Message: "password is too long for configured hashing algorithm"}
+	ErrInvalidCredentials = &Error{Kind: KindUnauthorized, Code: "invalid_credentials", Message: "invalid email or password"}
+	ErrInvalidToken       = &Error{Kind: KindUnauthorized, Code: "invalid_token", Message: "invalid or expired token"}
+	ErrWrongPassword      = &Error{Kind: KindForbidden, Code: "wrong_password", Message: "current password is incorrect"}
+	ErrEmailNotVerified   = &Error{Kind: KindForbidden, Code: "email_not_verified", Message: "email is not verified"}
+	ErrShuttingDown       = &Error{Kind: KindInternal, Code: "shutting_down", Message: "server is shutting down"}
+)
+
+// Codes of FieldError telling clients what is wrong with the field.
+const (
+	CodeRequired = "required"
+	CodeInvalid  = "invalid"
+	CodeTooLong  = "too_long"
+
+	msgRequired = "is required"
+)
+
+// FieldError describes single invalid field of the input.
+// Message is safe to be shown to the client.
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError collects every invalid field of the input,
+// so clients may fix all of them at once.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Error())
+	}
+
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// Add records problem with the field.
+func (e *ValidationError) Add(field, code, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Require records field as missing if its value is empty.
+func (e *ValidationError) Require(field, value string) {
+	if value == "" {
+		e.Add(field, CodeRequired, msgRequired)
+	}
+}
+
+// Err returns e if any field was added, otherwise nil.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+func (e *ValidationError) check(fieldErr *FieldError) {
+	if fieldErr != nil {
+		e.Fields = append(e.Fields, *fieldErr)
+	}
+}
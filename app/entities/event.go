@@ -0,0 +1,9 @@
+package entities
+
+// List of domain events of users.
+const (
+	EventUserSignedUp = "user_signed_up"
+	EventUserDeleted  = "user_deleted"
+	EventUserRestored = "user_restored"
+	EventUserPurged   = "user_purged"
+)
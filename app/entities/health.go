@@ -0,0 +1,19 @@
+package entities
+
+// Health is readiness report of the app.
+// Checks maps names of performed checks onto their errors,
+// which are nil for passed checks.
+type Health struct {
+	Checks map[string]error
+}
+
+// Ready reports whether all checks passed.
+func (h Health) Ready() bool {
+	for _, err := range h.Checks {
+		if err != nil {
+			return false
+		}
+	}
+
+	return true
+}
@@ -1,48 +1,327 @@
 package entities
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 // QueryParams represent request query params
 // that will be used on transport and repository level.
+// Users are paginated by offset when Offset is set
+// and by cursor otherwise.
+// Total number of matching users is counted only when Count is set,
+// so large tables do not pay for it on every call.
 type QueryParams struct {
 	Offset string
 	Limit  string
 	Sort   string
+	Cursor string
+	Count  string
+	Filter Filter
 }
 
-// Validate checks if QueryParameter fields are valid.
-func (qp QueryParams) Validate() error {
+// Filter narrows down list of users.
+// Empty fields do not filter anything.
+// Email has to match exactly, Name is a prefix
+// of either first or last name, and Search
+// is a free text looked up in names and email.
+type Filter struct {
+	Email          string
+	Name           string
+	CreatedAfter   string
+	CreatedBefore  string
+	IncludeDeleted string
+	Search         string
+}
+
+const (
+	maxFilterLen = 256
+	msgBoolean   = "must be a boolean"
+)
+
+// Validate checks if Filter fields are valid.
+func (f Filter) Validate() error {
+	var verr ValidationError
+
+	f.check(&verr)
+
+	return verr.Err()
+}
+
+// check records every invalid field of the filter.
+func (f Filter) check(verr *ValidationError) {
+	if f.Email != "" {
+		verr.check(checkMail("email", f.Email))
+	}
+
+	if len(f.Name) > maxFilterLen {
+		verr.Add("name", CodeTooLong, fmt.Sprintf("must be at most %d bytes long", maxFilterLen))
+	}
+
+	if len(f.Search) > maxFilterLen {
+		verr.Add("q", CodeTooLong, fmt.Sprintf("must be at most %d bytes long", maxFilterLen))
+	}
+
+	after, afterErr := parseFilterTime(verr, "created_after", f.CreatedAfter)
+	before, beforeErr := parseFilterTime(verr, "created_before", f.CreatedBefore)
+
+	if !afterErr && !beforeErr && !after.IsZero() && !before.IsZero() && !after.Before(before) {
+		verr.Add("created_after", CodeInvalid, "must be earlier than created_before")
+	}
+
+	if f.IncludeDeleted != "" {
+		if _, err := strconv.ParseBool(f.IncludeDeleted); err != nil {
+			verr.Add("include_deleted", CodeInvalid, msgBoolean)
+		}
+	}
+}
+
+// WithDeleted reports whether deleted users are included.
+func (f Filter) WithDeleted() bool {
+	include, _ := strconv.ParseBool(f.IncludeDeleted)
+
+	return include
+}
+
+// parseFilterTime parses time of the field
+// and reports whether it was recorded as invalid.
+func parseFilterTime(verr *ValidationError, field, value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		verr.Add(field, CodeInvalid, "must be RFC 3339 time, e.g. 2006-01-02T15:04:05Z")
+
+		return time.Time{}, true
+	}
+
+	return t, false
+}
+
+// List of columns users can be sorted by.
+const (
+	SortByFirstName = "first_name"
+	SortByLastName  = "last_name"
+	SortByCreatedAt = "created_at"
+)
+
+// SortKey is a column users are ordered by.
+type SortKey struct {
+	Column string
+	Desc   bool
+}
+
+// ParseSort parses comma separated list of columns,
+// each optionally prefixed with '-' for descending order,
+// e.g. "-created_at,last_name". Empty string yields default order.
+func ParseSort(s string) ([]SortKey, error) {
+	if s == "" {
+		return []SortKey{{Column: SortByFirstName}, {Column: SortByLastName}}, nil
+	}
+
+	items := strings.Split(s, ",")
+	keys := make([]SortKey, 0, len(items))
+	seen := make(map[string]bool, len(items))
+
+	for i, item := range items {
+		key := SortKey{Column: strings.TrimSpace(item)}
+
+		if strings.HasPrefix(key.Column, "-") {
+			key.Column, key.Desc = key.Column[1:], true
+		}
+
+		if key.Column == "" {
+			return nil, fmt.Errorf("sort key at position %d is empty", i+1)
+		}
+
+		if !isSortColumn(key.Column) {
+			return nil, fmt.Errorf("sort key '%v' is not one of %v", key.Column, []string{SortByFirstName, SortByLastName, SortByCreatedAt})
+		}
+
+		if seen[key.Column] {
+			return nil, fmt.Errorf("sort key '%v' is repeated", key.Column)
+		}
+
+		seen[key.Column] = true
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func isSortColumn(column string) bool {
+	switch column {
+	case SortByFirstName, SortByLastName, SortByCreatedAt:
+		return true
+	default:
+		return false
+	}
+}
+
+// SortValue returns value of the column user is sorted by.
+func (u User) SortValue(column string) string {
+	switch column {
+	case SortByFirstName:
+		return u.FirstName
+	case SortByLastName:
+		return u.LastName
+	case SortByCreatedAt:
+		return u.CreatedAt
+	default:
+		return ""
+	}
+}
+
+// Cursor points at the last user of the page,
+// so the next page starts right after it.
+// Keys hold on sort key values of that user.
+type Cursor struct {
+	Keys []string `json:"k"`
+	ID   string   `json:"id"`
+}
+
+// EncodeCursor turns cursor into opaque URL-safe string.
+func EncodeCursor(c Cursor) string {
+	b, _ := json.Marshal(c) //nolint:errchkjson // Marshaling of strings can not fail.
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses opaque string produced by EncodeCursor.
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrMalformedCursor.Wrap(err)
+	}
+
+	if err := json.Unmarshal(b, &c); err != nil {
+		return Cursor{}, ErrMalformedCursor.Wrap(err)
+	}
+
+	if _, err := uuid.Parse(c.ID); err != nil {
+		return Cursor{}, ErrMalformedCursor.Wrap(err)
+	}
+
+	return c, nil
+}
+
+// WithCount reports whether total number of matching users is requested.
+func (qp QueryParams) WithCount() bool {
+	count, _ := strconv.ParseBool(qp.Count)
+
+	return count
+}
+
+// UsesCursor reports whether users are paginated by cursor.
+func (qp QueryParams) UsesCursor() bool {
+	return qp.Offset == ""
+}
+
+// NextCursor returns cursor pointing at the last of given users
+// when paginating by cursor and the page is full, and empty string otherwise.
+func (qp QueryParams) NextCursor(users []User) string {
 	limit, err := strconv.Atoi(qp.Limit)
+	if !qp.UsesCursor() || err != nil || len(users) == 0 || len(users) < limit {
+		return ""
+	}
+
+	keys, err := ParseSort(qp.Sort)
 	if err != nil {
-		return fmt.Errorf("limit argument has to be a number")
+		return ""
+	}
+
+	last := users[len(users)-1]
+	values := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		values = append(values, last.SortValue(key.Column))
 	}
 
-	if limit < 0 {
-		return fmt.Errorf("limit argument has to be a positive number")
+	return EncodeCursor(Cursor{Keys: values, ID: last.ID})
+}
+
+// Validate checks if QueryParameter fields are valid
+// and reports every invalid one at once.
+func (qp QueryParams) Validate() error {
+	var verr ValidationError
+
+	if limit, err := strconv.Atoi(qp.Limit); err != nil {
+		verr.Add("limit", CodeInvalid, "must be a number")
+	} else if limit < 0 {
+		verr.Add("limit", CodeInvalid, "must be a positive number")
 	}
 
-	offset, err := strconv.Atoi(qp.Offset)
+	keys, err := ParseSort(qp.Sort)
 	if err != nil {
-		return fmt.Errorf("offset argument has to be a number")
+		verr.Add("sort", CodeInvalid, err.Error())
 	}
 
-	if offset < 0 {
-		return fmt.Errorf("offset argument has to be a positive number")
+	qp.Filter.check(&verr)
+
+	if qp.Count != "" {
+		if _, err := strconv.ParseBool(qp.Count); err != nil {
+			verr.Add("count", CodeInvalid, msgBoolean)
+		}
+	}
+
+	switch {
+	case qp.UsesCursor():
+		// Cursor can be checked against valid sort only.
+		if keys != nil {
+			qp.checkCursor(&verr, keys)
+		}
+	case qp.Cursor != "":
+		verr.Add("cursor", CodeInvalid, "can not be combined with offset")
+	default:
+		if offset, err := strconv.Atoi(qp.Offset); err != nil {
+			verr.Add("offset", CodeInvalid, "must be a number")
+		} else if offset < 0 {
+			verr.Add("offset", CodeInvalid, "must be a positive number")
+		}
+	}
+
+	return verr.Err()
+}
+
+// checkCursor checks that cursor holds on value of every sort key,
+// so cursor has to be used with the same sort it was issued for.
+func (qp QueryParams) checkCursor(verr *ValidationError, keys []SortKey) {
+	if qp.Cursor == "" {
+		return
 	}
 
-	allowedSortArgs := []string{"first_name", "last_name", "created_at", ","}
+	c, err := DecodeCursor(qp.Cursor)
+	if err != nil {
+		verr.Add("cursor", CodeInvalid, "is malformed")
 
-	for _, arg := range allowedSortArgs {
-		qp.Sort = strings.ReplaceAll(qp.Sort, arg, "")
+		return
 	}
 
-	if len(qp.Sort) > 0 {
-		return fmt.Errorf("sort argument '%v' does not fit list: %v", qp.Sort, allowedSortArgs)
+	if len(c.Keys) != len(keys) {
+		verr.Add("cursor", CodeInvalid, "does not match sort")
+
+		return
 	}
 
-	return nil
+	for i, key := range keys {
+		if key.Column != SortByCreatedAt {
+			continue
+		}
+
+		if _, err := time.Parse(time.RFC3339Nano, c.Keys[i]); err != nil {
+			verr.Add("cursor", CodeInvalid, "is malformed")
+
+			return
+		}
+	}
 }
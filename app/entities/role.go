@@ -0,0 +1,21 @@
+package entities
+
+// List of predefined roles.
+const (
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+// List of permissions that can be granted to roles.
+const (
+	PermUsersList   = "users:list"
+	PermUsersManage = "users:manage"
+	PermRolesManage = "roles:manage"
+)
+
+// Role groups permissions that can be assigned to users.
+type Role struct {
+	Name        string
+	Description string
+	Permissions []string
+}
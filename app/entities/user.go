@@ -15,7 +15,16 @@ type User struct {
 	LastName  string `json:"last_name"`
 	Password  string `json:"password"`
 	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
 	DeletedAt string `json:"deleted_at"`
+	// VerifiedAt and Roles can not be set by user, so they are never decoded from JSON.
+	VerifiedAt string   `json:"-"`
+	Roles      []string `json:"-"`
+}
+
+// IsVerified reports whether user confirmed ownership of the email.
+func (u User) IsVerified() bool {
+	return u.VerifiedAt != ""
 }
 
 const (
@@ -30,62 +39,109 @@ var (
 	passRegex = regexp.MustCompile(passMask)
 )
 
+// Validate checks every field of the user
+// reporting all invalid ones as *ValidationError.
 func (u User) Validate() error {
-	if err := checkName(u.FirstName); err != nil {
-		return fmt.Errorf("invalid first name: %w", err)
-	}
+	var verr ValidationError
+
+	verr.check(checkName("first_name", u.FirstName))
 
 	if len(u.LastName) > 1 {
-		if err := checkName(u.LastName); err != nil {
-			return fmt.Errorf("invalid last name: %w", err)
-		}
+		verr.check(checkName("last_name", u.LastName))
+	}
+
+	verr.check(checkMail("email", u.Email))
+	verr.check(checkPass("password", u.Password))
+
+	return verr.Err()
+}
+
+// UserPatch holds on profile fields that are going to be changed.
+// Nil field stays untouched.
+type UserPatch struct {
+	FirstName *string
+	LastName  *string
+	Email     *string
+}
+
+// IsEmpty reports whether patch changes nothing.
+func (p UserPatch) IsEmpty() bool {
+	return p.FirstName == nil && p.LastName == nil && p.Email == nil
+}
+
+// Validate checks each field that is going to be changed
+// with the same rules as User.Validate does.
+func (p UserPatch) Validate() error {
+	var verr ValidationError
+
+	if p.FirstName != nil {
+		verr.check(checkName("first_name", *p.FirstName))
 	}
 
-	if err := checkMail(u.Email); err != nil {
-		return err
+	if p.LastName != nil && len(*p.LastName) > 1 {
+		verr.check(checkName("last_name", *p.LastName))
 	}
 
-	if err := checkPass(u.Password); err != nil {
-		return err
+	if p.Email != nil {
+		verr.check(checkMail("email", *p.Email))
 	}
 
-	return nil
+	return verr.Err()
 }
 
-func checkName(name string) error {
+func checkName(field, name string) *FieldError {
+	if name == "" {
+		return &FieldError{Field: field, Code: CodeRequired, Message: msgRequired}
+	}
+
 	if valid := nameRegex.MatchString(name); !valid {
-		return fmt.Errorf("name does not match with regex: `%s`", nameMask)
+		return &FieldError{Field: field, Code: CodeInvalid, Message: "must be 2 to 256 letters, spaces, hyphens, apostrophes, dots or ampersands"}
 	}
 
 	return nil
 }
 
-func checkMail(email string) error {
+func checkMail(field, email string) *FieldError {
 	const (
 		maxLocalBytes  int = 64
 		maxDomainBytes int = 255
 	)
+
+	if email == "" {
+		return &FieldError{Field: field, Code: CodeRequired, Message: msgRequired}
+	}
+
 	// Checking the lengths of local and domain parts.
 	atIndex := strings.IndexByte(email, '@')
 	if atIndex > maxLocalBytes {
-		return fmt.Errorf("local part of email contains too many bytes: %v", atIndex)
+		return &FieldError{Field: field, Code: CodeTooLong, Message: fmt.Sprintf("local part must be at most %d bytes", maxLocalBytes)}
 	}
 
 	if localPartLen := len(email) - atIndex - 1; localPartLen > maxDomainBytes {
-		return fmt.Errorf("domain part of email contains too many bytes: %v", localPartLen)
+		return &FieldError{Field: field, Code: CodeTooLong, Message: fmt.Sprintf("domain part must be at most %d bytes", maxDomainBytes)}
 	}
 
 	// Checking for other email issues by regular expression.
 	if valid := mailRegex.MatchString(email); !valid {
-		return fmt.Errorf("email does not match with regex: `%s`", mailMask)
+		return &FieldError{Field: field, Code: CodeInvalid, Message: "must be a valid email address"}
 	}
 
 	return nil
 }
 
-func checkPass(password string) error {
+// CheckPassword records field if password does not follow
+// the same rules as User.Validate checks.
+func (e *ValidationError) CheckPassword(field, password string) {
+	e.check(checkPass(field, password))
+}
+
+func checkPass(field, password string) *FieldError {
+	if password == "" {
+		return &FieldError{Field: field, Code: CodeRequired, Message: msgRequired}
+	}
+
 	if valid := passRegex.MatchString(password); !valid {
-		return fmt.Errorf("password does not match with regex: `%s`", passMask)
+		return &FieldError{Field: field, Code: CodeInvalid, Message: "must be 8 to 256 printable characters without spaces"}
 	}
 
 	return nil
@@ -0,0 +1,89 @@
+// Package metrics lives in app dir and represents adapter layer
+// which exposes instrumentation of the app in Prometheus text format.
+package metrics
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "octagon"
+
+// Metrics keeps collectors of the app in its own registry,
+// so nothing but registered here is exposed.
+type Metrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	events   *prometheus.CounterVec
+}
+
+// New will initialise new instance of Metrics
+// collecting Go runtime and process metrics as well.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of served HTTP requests.",
+		}, []string{"route", "method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of served HTTP requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_total",
+			Help:      "Number of occurred domain events.",
+		}, []string{"event"}),
+	}
+
+	m.registry.MustRegister(
+		m.requests,
+		m.duration,
+		m.events,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
+
+// RegisterDB exposes connection pool stats of the database.
+func (m *Metrics) RegisterDB(db *sql.DB, name string) error {
+	if err := m.registry.Register(collectors.NewDBStatsCollector(db, name)); err != nil {
+		return fmt.Errorf("error registering database stats collector: %w", err)
+	}
+
+	return nil
+}
+
+// ObserveRequest records served request.
+func (m *Metrics) ObserveRequest(route, method string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+
+	m.requests.WithLabelValues(route, method, code).Inc()
+	m.duration.WithLabelValues(route, method, code).Observe(duration.Seconds())
+}
+
+// Record counts occurred domain event.
+func (m *Metrics) Record(event string, count int64) {
+	m.events.WithLabelValues(event).Add(float64(count))
+}
+
+// Handler serves collected metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+
+	resp := httptest.NewRecorder()
+	m.Handler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	return resp.Body.String()
+}
+
+func TestMetrics(t *testing.T) {
+	m := New()
+
+	m.ObserveRequest("/users/{id}", http.MethodGet, http.StatusOK, 30*time.Millisecond)
+	m.ObserveRequest("/users/{id}", http.MethodGet, http.StatusOK, 2*time.Second)
+	m.ObserveRequest("/users/{id}", http.MethodGet, http.StatusNotFound, time.Millisecond)
+	m.Record(entities.EventUserSignedUp, 1)
+	m.Record(entities.EventUserPurged, 3)
+
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	require.NoError(t, m.RegisterDB(db, "sqlite"))
+	require.Error(t, m.RegisterDB(db, "sqlite"))
+
+	body := scrape(t, m)
+
+	for _, line := range []string{
+		`octagon_http_requests_total{method="GET",route="/users/{id}",status="200"} 2`,
+		`octagon_http_requests_total{method="GET",route="/users/{id}",status="404"} 1`,
+		`octagon_http_request_duration_seconds_bucket{method="GET",route="/users/{id}",status="200",le="0.05"} 1`,
+		`octagon_http_request_duration_seconds_count{method="GET",route="/users/{id}",status="200"} 2`,
+		`octagon_events_total{event="user_signed_up"} 1`,
+		`octagon_events_total{event="user_purged"} 3`,
+		`go_sql_max_open_connections{db_name="sqlite"} 0`,
+		`go_goroutines`,
+	} {
+		require.Contains(t, body, line)
+	}
+}
@@ -0,0 +1,111 @@
+// Package notifier lives in app dir and represents adapter layer
+// which delivers notifications to users.
+// Implementations below do not send anything over the network,
+// so the app can be run offline.
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/go-devs-ua/octagon/lgr"
+)
+
+const filePerm = 0o600
+
+// Log writes notifications to the log.
+// It is meant for local development only,
+// as notifications may carry secrets like one-time tokens.
+type Log struct {
+	logger *lgr.Logger
+}
+
+// NewLog will initialise new instance of Log.
+func NewLog(logger *lgr.Logger) Log {
+	return Log{logger: logger}
+}
+
+// Notify writes notification to the log.
+func (n Log) Notify(ctx context.Context, msg entities.Notification) error {
+	n.logger.Infow("Notification", "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+
+	return nil
+}
+
+// File appends notifications to the file as JSON lines.
+type File struct {
+	path string
+	mu   *sync.Mutex
+}
+
+// NewFile will initialise new instance of File.
+func NewFile(path string) File {
+	return File{path: path, mu: new(sync.Mutex)}
+}
+
+type fileRecord struct {
+	Time    time.Time `json:"time"`
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+}
+
+// Notify appends notification to the file.
+func (n File) Notify(ctx context.Context, msg entities.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, filePerm)
+	if err != nil {
+		return fmt.Errorf("error opening notifications file: %w", err)
+	}
+
+	record := fileRecord{Time: time.Now(), To: msg.To, Subject: msg.Subject, Body: msg.Body}
+
+	if err := json.NewEncoder(f).Encode(record); err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("error writing notification: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error closing notifications file: %w", err)
+	}
+
+	return nil
+}
+
+// Memory keeps notifications in memory.
+// It is meant for tests, which can inspect sent notifications.
+type Memory struct {
+	mu   *sync.Mutex
+	sent *[]entities.Notification
+}
+
+// NewMemory will initialise new instance of Memory.
+func NewMemory() Memory {
+	return Memory{mu: new(sync.Mutex), sent: new([]entities.Notification)}
+}
+
+// Notify stores notification in memory.
+func (n Memory) Notify(ctx context.Context, msg entities.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	*n.sent = append(*n.sent, msg)
+
+	return nil
+}
+
+// Sent returns copy of notifications sent so far.
+func (n Memory) Sent() []entities.Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]entities.Notification(nil), *n.sent...)
+}
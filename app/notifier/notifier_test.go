@@ -0,0 +1,47 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_Notify(t *testing.T) {
+	n := NewMemory()
+	msg := entities.Notification{To: "john@example.com", Subject: "Email verification", Body: "token"}
+
+	require.NoError(t, n.Notify(context.Background(), msg))
+	require.Equal(t, []entities.Notification{msg}, n.Sent())
+}
+
+func TestFile_Notify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.jsonl")
+	n := NewFile(path)
+
+	for _, to := range []string{"john@example.com", "jane@example.com"} {
+		require.NoError(t, n.Notify(context.Background(), entities.Notification{To: to, Subject: "Password reset", Body: "token"}))
+	}
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	var recipients []string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record fileRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+
+		recipients = append(recipients, record.To)
+	}
+
+	require.Equal(t, []string{"john@example.com", "jane@example.com"}, recipients)
+}
@@ -0,0 +1,145 @@
+// Package memory lives in repository dir and represents adapter layer
+// which keeps all data in memory. It has the same semantics as pg.Repo
+// and lets the app run locally or in tests without a database.
+// Data is lost when the app stops.
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/google/uuid"
+)
+
+// Repo keeps data in memory guarded by mutex,
+// so it is safe for concurrent use.
+type Repo struct {
+	mu            *sync.RWMutex
+	users         map[string]*userRow
+	refreshTokens map[string]*entities.RefreshToken
+	userTokens    map[string]*userTokenRow
+	roles         map[string]entities.Role
+}
+
+// NewRepo will initialise new instance of Repo
+// seeded with the same roles as database migrations seed.
+func NewRepo() *Repo {
+	return &Repo{
+		mu:            new(sync.RWMutex),
+		users:         make(map[string]*userRow),
+		refreshTokens: make(map[string]*entities.RefreshToken),
+		userTokens:    make(map[string]*userTokenRow),
+		roles: map[string]entities.Role{
+			entities.RoleAdmin: {
+				Name:        entities.RoleAdmin,
+				Description: "Back-office staff moderating accounts",
+				Permissions: []string{entities.PermRolesManage, entities.PermUsersList, entities.PermUsersManage},
+			},
+			entities.RoleMember: {
+				Name:        entities.RoleMember,
+				Description: "Regular user",
+			},
+		},
+	}
+}
+
+type userRow struct {
+	id           string
+	firstName    string
+	lastName     string
+	email        string
+	password     string
+	createdAt    time.Time
+	updatedAt    time.Time
+	verifiedAt   *time.Time
+	deletedAt    *time.Time
+	anonymizedAt *time.Time
+	roles        map[string]bool
+}
+
+func (u *userRow) isDeleted() bool {
+	return u.deletedAt != nil
+}
+
+// touch mimics trigger keeping updated_at of the user.
+func (u *userRow) touch() {
+	u.updatedAt = now()
+}
+
+// entity returns copy of the row. Password is returned only when asked.
+func (u *userRow) entity(withPassword bool) entities.User {
+	user := entities.User{
+		ID:         u.id,
+		FirstName:  u.firstName,
+		LastName:   u.lastName,
+		Email:      u.email,
+		CreatedAt:  formatTime(&u.createdAt),
+		UpdatedAt:  formatTime(&u.updatedAt),
+		VerifiedAt: formatTime(u.verifiedAt),
+		DeletedAt:  formatTime(u.deletedAt),
+		Roles:      make([]string, 0, len(u.roles)),
+	}
+
+	if withPassword {
+		user.Password = u.password
+	}
+
+	for role := range u.roles {
+		user.Roles = append(user.Roles, role)
+	}
+
+	sort.Strings(user.Roles)
+
+	return user
+}
+
+type userTokenRow struct {
+	entities.UserToken
+	usedAt *time.Time
+}
+
+// now returns current time rounded to microseconds,
+// which is precision of timestamps in database.
+func now() time.Time {
+	return time.Now().UTC().Round(time.Microsecond)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339Nano)
+}
+
+func newID() string {
+	return uuid.NewString()
+}
+
+// emailTaken reports whether email belongs to not deleted user other than given one.
+func (r *Repo) emailTaken(email, exceptID string) bool {
+	for _, u := range r.users {
+		if !u.isDeleted() && u.id != exceptID && u.email == email {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeUserData mimics cascade deletion of data belonging to the user.
+func (r *Repo) removeUserData(userID string) {
+	for hash, t := range r.refreshTokens {
+		if t.UserID == userID {
+			delete(r.refreshTokens, hash)
+		}
+	}
+
+	for hash, t := range r.userTokens {
+		if t.UserID == userID {
+			delete(r.userTokens, hash)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+)
+
+// filterUsers returns users matching the filter
+// the same way pg.userFilter matches rows.
+func (r *Repo) filterUsers(f entities.Filter) []entities.User {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var (
+		after, _  = time.Parse(time.RFC3339, f.CreatedAfter)
+		before, _ = time.Parse(time.RFC3339, f.CreatedBefore)
+		users     []entities.User
+	)
+
+	for _, row := range r.users {
+		switch {
+		case row.isDeleted() && !f.WithDeleted():
+		case f.Email != "" && row.email != f.Email:
+		case f.Name != "" && !hasPrefixFold(row.firstName, f.Name) && !hasPrefixFold(row.lastName, f.Name):
+		case f.CreatedAfter != "" && !row.createdAt.After(after):
+		case f.CreatedBefore != "" && !row.createdAt.Before(before):
+		case f.Search != "" && !matchesSearch(row, f.Search):
+		default:
+			users = append(users, row.entity(false))
+		}
+	}
+
+	return users
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// matchesSearch approximates full-text search:
+// user matches when the text is a substring of names and email
+// or when every word of the text is one of their words.
+func matchesSearch(row *userRow, search string) bool {
+	text := strings.ToLower(row.firstName + " " + row.lastName + " " + row.email)
+	search = strings.ToLower(search)
+
+	if strings.Contains(text, search) {
+		return true
+	}
+
+	words := make(map[string]bool)
+
+	for _, word := range strings.FieldsFunc(text, isSeparator) {
+		words[word] = true
+	}
+
+	terms := strings.FieldsFunc(search, isSeparator)
+	for _, term := range terms {
+		if !words[term] {
+			return false
+		}
+	}
+
+	return len(terms) > 0
+}
+
+func isSeparator(r rune) bool {
+	return strings.ContainsRune(" \t\n@.-_+", r)
+}
+
+// sortUsers orders users by keys with id as the last key, so order is stable.
+func sortUsers(users []entities.User, keys []entities.SortKey) {
+	sort.Slice(users, func(i, j int) bool {
+		return compareUsers(users[i], users[j].ID, valuesOf(users[j], keys), keys) < 0
+	})
+}
+
+// usersAfter returns sorted users which follow the cursor.
+func usersAfter(users []entities.User, keys []entities.SortKey, cursor entities.Cursor) []entities.User {
+	for i, user := range users {
+		if compareUsers(user, cursor.ID, cursor.Keys, keys) > 0 {
+			return users[i:]
+		}
+	}
+
+	return nil
+}
+
+func valuesOf(user entities.User, keys []entities.SortKey) []string {
+	values := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		values = append(values, user.SortValue(key.Column))
+	}
+
+	return values
+}
+
+// compareUsers compares user with position given by sort key values and id
+// in order given by keys and returns -1, 0 or 1.
+func compareUsers(user entities.User, id string, values []string, keys []entities.SortKey) int {
+	for i, key := range keys {
+		c := compareValues(key.Column, user.SortValue(key.Column), values[i])
+		if key.Desc {
+			c = -c
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+
+	return strings.Compare(user.ID, id)
+}
+
+func compareValues(column, a, b string) int {
+	if column == entities.SortByCreatedAt {
+		ta, _ := time.Parse(time.RFC3339Nano, a)
+		tb, _ := time.Parse(time.RFC3339Nano, b)
+
+		switch {
+		case ta.Before(tb):
+			return -1
+		case ta.After(tb):
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	return strings.Compare(a, b)
+}
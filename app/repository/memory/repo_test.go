@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/go-devs-ua/octagon/app/repository/repotest"
+)
+
+func TestRepo(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repository {
+		return NewRepo()
+	})
+}
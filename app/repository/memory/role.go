@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+)
+
+// ListRoles retrieves all roles along with their permissions.
+func (r *Repo) ListRoles(ctx context.Context) ([]entities.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	roles := make([]entities.Role, 0, len(r.roles))
+
+	for _, role := range r.roles {
+		role.Permissions = append([]string(nil), role.Permissions...)
+		roles = append(roles, role)
+	}
+
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+
+	return roles, nil
+}
+
+// FindPermissions retrieves distinct permissions granted by given roles.
+func (r *Repo) FindPermissions(ctx context.Context, roles []string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var (
+		seen        = make(map[string]bool)
+		permissions []string
+	)
+
+	for _, name := range roles {
+		for _, permission := range r.roles[name].Permissions {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+
+	sort.Strings(permissions)
+
+	return permissions, nil
+}
+
+// AssignRole grants role to the user. Assigning already granted role is a no-op.
+func (r *Repo) AssignRole(ctx context.Context, userID, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.roles[role]; !ok {
+		return entities.ErrRoleNotFound
+	}
+
+	row, ok := r.users[userID]
+	if !ok {
+		return entities.ErrNotFound
+	}
+
+	row.roles[role] = true
+
+	return nil
+}
+
+// RevokeRole takes role away from the user. Revoking role that is not granted is a no-op.
+func (r *Repo) RevokeRole(ctx context.Context, userID, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if row, ok := r.users[userID]; ok {
+		delete(row.roles, role)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+)
+
+// AddRefreshToken stores hash of issued refresh token.
+func (r *Repo) AddRefreshToken(ctx context.Context, token entities.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token.ID = newID()
+	token.RevokedAt = nil
+	r.refreshTokens[token.TokenHash] = &token
+
+	return nil
+}
+
+// FindRefreshToken finds refresh token by its hash regardless of its state.
+func (r *Repo) FindRefreshToken(ctx context.Context, tokenHash string) (*entities.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	token, ok := r.refreshTokens[tokenHash]
+	if !ok {
+		return nil, entities.ErrTokenNotFound
+	}
+
+	found := *token
+
+	return &found, nil
+}
+
+// RevokeRefreshToken atomically revokes active (not revoked and not expired)
+// refresh token and returns it, so the same token can not be used twice.
+func (r *Repo) RevokeRefreshToken(ctx context.Context, tokenHash string) (*entities.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.refreshTokens[tokenHash]
+	if !ok || token.RevokedAt != nil || !token.ExpiresAt.After(time.Now()) {
+		return nil, entities.ErrTokenNotFound
+	}
+
+	t := now()
+	token.RevokedAt = &t
+	revoked := *token
+
+	return &revoked, nil
+}
+
+// DeleteRefreshToken deletes refresh token whether it is active or not.
+// Deleting unknown token is not an error.
+func (r *Repo) DeleteRefreshToken(ctx context.Context, tokenHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.refreshTokens, tokenHash)
+
+	return nil
+}
+
+// DeleteUserRefreshTokens deletes all refresh tokens of the user,
+// including revoked ones, so none of them is recognised anymore.
+func (r *Repo) DeleteUserRefreshTokens(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for tokenHash, token := range r.refreshTokens {
+		if token.UserID == userID {
+			delete(r.refreshTokens, tokenHash)
+		}
+	}
+
+	return nil
+}
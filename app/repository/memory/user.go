@@ -0,0 +1,262 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+)
+
+// AddUser stores the user along with assigning default member role.
+// Password is expected to be already hashed.
+func (r *Repo) AddUser(ctx context.Context, user entities.User) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.emailTaken(user.Email, "") {
+		return "", entities.ErrDuplicateEmail
+	}
+
+	created := now()
+	row := &userRow{
+		id:        newID(),
+		firstName: user.FirstName,
+		lastName:  user.LastName,
+		email:     user.Email,
+		password:  user.Password,
+		createdAt: created,
+		updatedAt: created,
+		roles:     map[string]bool{entities.RoleMember: true},
+	}
+
+	r.users[row.id] = row
+
+	return row.id, nil
+}
+
+// FindUser finds not deleted user by ID.
+func (r *Repo) FindUser(ctx context.Context, id string) (*entities.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	row, ok := r.users[id]
+	if !ok || row.isDeleted() {
+		return nil, entities.ErrNotFound
+	}
+
+	user := row.entity(false)
+
+	return &user, nil
+}
+
+// FindUserByEmail finds not deleted user by email.
+// Unlike FindUser it also returns password hash, so it can be used for authentication.
+func (r *Repo) FindUserByEmail(ctx context.Context, email string) (*entities.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, row := range r.users {
+		if !row.isDeleted() && row.email == email {
+			user := row.entity(true)
+
+			return &user, nil
+		}
+	}
+
+	return nil, entities.ErrNotFound
+}
+
+// GetAllUsers retrieves page of users matching params.
+func (r *Repo) GetAllUsers(ctx context.Context, params entities.QueryParams) ([]entities.User, error) {
+	keys, err := entities.ParseSort(params.Sort)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing sort: %w", err)
+	}
+
+	limit, err := strconv.Atoi(params.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing limit: %w", err)
+	}
+
+	users := r.filterUsers(params.Filter)
+	sortUsers(users, keys)
+
+	if params.UsesCursor() {
+		if params.Cursor != "" {
+			cursor, err := entities.DecodeCursor(params.Cursor)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding cursor: %w", err)
+			}
+
+			if len(cursor.Keys) != len(keys) {
+				return nil, entities.ErrMalformedCursor
+			}
+
+			users = usersAfter(users, keys, cursor)
+		}
+	} else {
+		offset, err := strconv.Atoi(params.Offset)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing offset: %w", err)
+		}
+
+		if offset > len(users) {
+			offset = len(users)
+		}
+
+		users = users[offset:]
+	}
+
+	if limit < len(users) {
+		users = users[:limit]
+	}
+
+	return users, nil
+}
+
+// CountUsers counts users matching the filter.
+func (r *Repo) CountUsers(ctx context.Context, filter entities.Filter) (int64, error) {
+	return int64(len(r.filterUsers(filter))), nil
+}
+
+// UpdateUser applies patch to the user and returns updated user.
+// Changing email resets its verification.
+func (r *Repo) UpdateUser(ctx context.Context, id string, patch entities.UserPatch) (*entities.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.users[id]
+	if !ok || row.isDeleted() {
+		return nil, entities.ErrNotFound
+	}
+
+	if patch.Email != nil && *patch.Email != row.email {
+		if r.emailTaken(*patch.Email, id) {
+			return nil, entities.ErrDuplicateEmail
+		}
+
+		row.email, row.verifiedAt = *patch.Email, nil
+	}
+
+	if patch.FirstName != nil {
+		row.firstName = *patch.FirstName
+	}
+
+	if patch.LastName != nil {
+		row.lastName = *patch.LastName
+	}
+
+	row.touch()
+
+	user := row.entity(false)
+
+	return &user, nil
+}
+
+// MarkVerified records that user confirmed ownership of the email.
+// Verifying already verified user keeps original verification time.
+func (r *Repo) MarkVerified(ctx context.Context, id string) error {
+	return r.updateUser(id, func(row *userRow) {
+		if row.verifiedAt == nil {
+			t := now()
+			row.verifiedAt = &t
+		}
+	})
+}
+
+// UpdatePassword replaces password hash of the user.
+func (r *Repo) UpdatePassword(ctx context.Context, user entities.User) error {
+	return r.updateUser(user.ID, func(row *userRow) {
+		row.password = user.Password
+	})
+}
+
+// DeleteUser marks user deleted.
+func (r *Repo) DeleteUser(ctx context.Context, user entities.User) error {
+	return r.updateUser(user.ID, func(row *userRow) {
+		t := now()
+		row.deletedAt = &t
+	})
+}
+
+// RestoreUser undoes deletion of the user deleted after given time
+// and returns restored user.
+func (r *Repo) RestoreUser(ctx context.Context, id string, deletedAfter time.Time) (*entities.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.users[id]
+	if !ok || !row.isDeleted() || !row.deletedAt.After(deletedAfter) || row.anonymizedAt != nil {
+		return nil, entities.ErrNotFound
+	}
+
+	if r.emailTaken(row.email, id) {
+		return nil, entities.ErrDuplicateEmail
+	}
+
+	row.deletedAt = nil
+	row.touch()
+
+	user := row.entity(false)
+
+	return &user, nil
+}
+
+// PurgeUsers removes users deleted before given time
+// along with all their data and returns number of removed users.
+func (r *Repo) PurgeUsers(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+
+	for id, row := range r.users {
+		if row.isDeleted() && row.deletedAt.Before(deletedBefore) {
+			delete(r.users, id)
+			r.removeUserData(id)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// AnonymizeUsers wipes personal data and credentials of users deleted before given time,
+// keeping users themselves, and returns number of anonymised users.
+func (r *Repo) AnonymizeUsers(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+
+	for id, row := range r.users {
+		if row.isDeleted() && row.deletedAt.Before(deletedBefore) && row.anonymizedAt == nil {
+			t := now()
+			row.firstName, row.lastName, row.email, row.password = "Deleted", "", "deleted-"+id+"@invalid", ""
+			row.anonymizedAt = &t
+			row.touch()
+			r.removeUserData(id)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// updateUser applies change to not deleted user.
+func (r *Repo) updateUser(id string, change func(*userRow)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.users[id]
+	if !ok || row.isDeleted() {
+		return entities.ErrNotFound
+	}
+
+	change(row)
+	row.touch()
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+)
+
+// AddUserToken stores hash of issued one-time token.
+func (r *Repo) AddUserToken(ctx context.Context, token entities.UserToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token.ID = newID()
+	r.userTokens[token.TokenHash] = &userTokenRow{UserToken: token}
+
+	return nil
+}
+
+// ConsumeUserToken atomically marks unused and not expired token
+// issued for given purpose as used and returns it.
+func (r *Repo) ConsumeUserToken(ctx context.Context, tokenHash, purpose string) (*entities.UserToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.userTokens[tokenHash]
+	if !ok || row.Purpose != purpose || row.usedAt != nil || !row.ExpiresAt.After(time.Now()) {
+		return nil, entities.ErrTokenNotFound
+	}
+
+	t := now()
+	row.usedAt = &t
+	token := row.UserToken
+
+	return &token, nil
+}
+
+// RevokeUserTokens marks all unused tokens issued to the user
+// for given purpose as used, so none of them can be consumed anymore.
+func (r *Repo) RevokeUserTokens(ctx context.Context, userID, purpose string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := now()
+
+	for _, row := range r.userTokens {
+		if row.UserID == userID && row.Purpose == purpose && row.usedAt == nil {
+			row.usedAt = &t
+		}
+	}
+
+	return nil
+}
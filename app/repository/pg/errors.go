@@ -0,0 +1,55 @@
+package pg
+
+import (
+	"errors"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/lib/pq"
+)
+
+// Names of postgres error codes translated into domain errors.
+const (
+	ErrCodeUniqueViolation      = "unique_violation"
+	ErrCodeForeignKeyViolation  = "foreign_key_violation"
+	ErrCodeCheckViolation       = "check_violation"
+	ErrCodeSerializationFailure = "serialization_failure"
+)
+
+// Constraints violation of which has specific meaning.
+const (
+	uniqueUserEmail = "unique_user_email"
+	fkUserRoleRole  = "fk_user_role_role"
+	fkUserRoleUser  = "fk_user_role_user"
+)
+
+// domainError translates postgres error into domain error keeping it as the cause.
+// Violated constraint is looked up first, then the error code.
+// Errors which are not reported by postgres are returned as is.
+func domainError(err error) error {
+	pqErr := new(pq.Error)
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Constraint {
+	case uniqueUserEmail:
+		return entities.ErrDuplicateEmail.Wrap(err)
+	case fkUserRoleRole:
+		return entities.ErrRoleNotFound.Wrap(err)
+	case fkUserRoleUser:
+		return entities.ErrNotFound.Wrap(err)
+	}
+
+	switch pqErr.Code.Name() {
+	case ErrCodeUniqueViolation:
+		return entities.ErrConflict.Wrap(err)
+	case ErrCodeForeignKeyViolation:
+		return entities.ErrReferenceNotFound.Wrap(err)
+	case ErrCodeCheckViolation:
+		return entities.ErrConstraint.Wrap(err)
+	case ErrCodeSerializationFailure:
+		return entities.ErrConcurrentUpdate.Wrap(err)
+	}
+
+	return err
+}
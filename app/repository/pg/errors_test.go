@@ -0,0 +1,64 @@
+package pg
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDomainError(t *testing.T) {
+	tests := map[string]struct {
+		err    error
+		expErr error
+	}{
+		"duplicate_email": {
+			err:    &pq.Error{Code: "23505", Constraint: uniqueUserEmail},
+			expErr: entities.ErrDuplicateEmail,
+		},
+		"unique_violation": {
+			err:    &pq.Error{Code: "23505", Constraint: "unique_refresh_token_hash"},
+			expErr: entities.ErrConflict,
+		},
+		"unknown_role": {
+			err:    fmt.Errorf("exec: %w", &pq.Error{Code: "23503", Constraint: fkUserRoleRole}),
+			expErr: entities.ErrRoleNotFound,
+		},
+		"unknown_user": {
+			err:    &pq.Error{Code: "23503", Constraint: fkUserRoleUser},
+			expErr: entities.ErrNotFound,
+		},
+		"foreign_key_violation": {
+			err:    &pq.Error{Code: "23503", Constraint: "fk_user_token_user"},
+			expErr: entities.ErrReferenceNotFound,
+		},
+		"check_violation": {
+			err:    &pq.Error{Code: "23514"},
+			expErr: entities.ErrConstraint,
+		},
+		"serialization_failure": {
+			err:    &pq.Error{Code: "40001"},
+			expErr: entities.ErrConcurrentUpdate,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := domainError(tt.err)
+
+			require.ErrorIs(t, err, tt.expErr)
+			require.ErrorIs(t, err, tt.err, "cause has to be kept")
+		})
+	}
+
+	t.Run("not_translated", func(t *testing.T) {
+		syntaxErr := &pq.Error{Code: "42601"}
+		require.Same(t, syntaxErr, domainError(syntaxErr))
+
+		plainErr := errors.New("driver: bad connection")
+		require.Same(t, plainErr, domainError(plainErr))
+	})
+}
@@ -0,0 +1,148 @@
+package pg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+)
+
+// sortExpression maps column users can be sorted by
+// onto SQL expression and type of cursor values compared with it.
+// Only these expressions ever get into ORDER BY, so sort can not inject SQL.
+// Columns are validated by entities.ParseSort, anything else falls back to id.
+func sortExpression(column string) (expr, cast string) {
+	switch column {
+	case entities.SortByFirstName:
+		return "first_name", "text"
+	case entities.SortByLastName:
+		return "COALESCE(last_name, '')", "text"
+	case entities.SortByCreatedAt:
+		return "created_at", "timestamp"
+	default:
+		return "id", "uuid"
+	}
+}
+
+// orderBy turns sort keys into ORDER BY list
+// with id as the last key, so order is stable.
+func orderBy(keys []entities.SortKey) string {
+	exprs := make([]string, 0, len(keys)+1)
+
+	for _, key := range keys {
+		expr, _ := sortExpression(key.Column)
+		exprs = append(exprs, expr+direction(key.Desc))
+	}
+
+	return strings.Join(append(exprs, "id ASC"), ", ")
+}
+
+// keysetAfter builds predicate matching rows which follow the cursor
+// in order given by keys, and values of its placeholders numbered from first.
+// As keys may have different directions, row comparison can not be used:
+//
+//	k1 > $1 OR (k1 = $1 AND k2 < $2) OR (k1 = $1 AND k2 = $2 AND id > $3)
+func keysetAfter(keys []entities.SortKey, cursor entities.Cursor, first int) (string, []any) {
+	var (
+		disjuncts = make([]string, 0, len(keys)+1)
+		equals    = make([]string, 0, len(keys))
+		args      = make([]any, 0, len(keys)+1)
+	)
+
+	for i, key := range keys {
+		expr, cast := sortExpression(key.Column)
+		placeholder := fmt.Sprintf("$%d::%s", first+i, cast)
+
+		disjuncts = append(disjuncts, "("+strings.Join(append(equals, expr+comparison(key.Desc)+placeholder), " AND ")+")")
+		equals = append(equals, expr+" = "+placeholder)
+		args = append(args, cursor.Keys[i])
+	}
+
+	placeholder := fmt.Sprintf("$%d::uuid", first+len(keys))
+	disjuncts = append(disjuncts, "("+strings.Join(append(equals, "id > "+placeholder), " AND ")+")")
+	args = append(args, cursor.ID)
+
+	return strings.Join(disjuncts, " OR "), args
+}
+
+// conditions collects SQL conditions joined by AND
+// along with values of their placeholders.
+type conditions struct {
+	exprs []string
+	args  []any
+}
+
+// add appends condition in which %[1]s, %[2]s, etc.
+// stand for placeholders of given values.
+func (c *conditions) add(expr string, args ...any) {
+	placeholders := make([]any, 0, len(args))
+
+	for _, arg := range args {
+		c.args = append(c.args, arg)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(c.args)))
+	}
+
+	c.exprs = append(c.exprs, fmt.Sprintf(expr, placeholders...))
+}
+
+func (c *conditions) String() string {
+	if len(c.exprs) == 0 {
+		return "TRUE"
+	}
+
+	return strings.Join(c.exprs, " AND ")
+}
+
+// userFilter turns filter into conditions.
+// Filter values never get into SQL text, only into placeholders.
+func userFilter(f entities.Filter) *conditions {
+	c := new(conditions)
+
+	if !f.WithDeleted() {
+		c.exprs = append(c.exprs, "deleted_at IS NULL")
+	}
+
+	if f.Email != "" {
+		c.add("email = %[1]s", f.Email)
+	}
+
+	if f.Name != "" {
+		c.add("(first_name ILIKE %[1]s OR last_name ILIKE %[1]s)", escapeLike(f.Name)+"%")
+	}
+
+	if f.CreatedAfter != "" {
+		c.add("created_at > %[1]s::timestamptz", f.CreatedAfter)
+	}
+
+	if f.CreatedBefore != "" {
+		c.add("created_at < %[1]s::timestamptz", f.CreatedBefore)
+	}
+
+	if f.Search != "" {
+		c.add("(search_vector @@ plainto_tsquery('simple', %[1]s) OR search_text LIKE %[2]s)",
+			f.Search, "%"+escapeLike(strings.ToLower(f.Search))+"%")
+	}
+
+	return c
+}
+
+// escapeLike escapes wildcards, so value is matched literally by LIKE.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+
+	return " ASC"
+}
+
+func comparison(desc bool) string {
+	if desc {
+		return " < "
+	}
+
+	return " > "
+}
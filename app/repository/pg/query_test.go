@@ -0,0 +1,46 @@
+package pg
+
+import (
+	"testing"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderBy(t *testing.T) {
+	keys := []entities.SortKey{{Column: entities.SortByCreatedAt, Desc: true}, {Column: entities.SortByLastName}}
+
+	require.Equal(t, "created_at DESC, COALESCE(last_name, '') ASC, id ASC", orderBy(keys))
+}
+
+func TestKeysetAfter(t *testing.T) {
+	keys := []entities.SortKey{{Column: entities.SortByCreatedAt, Desc: true}, {Column: entities.SortByFirstName}}
+	cursor := entities.Cursor{
+		Keys: []string{"2022-11-05T22:28:36.679554Z", "John"},
+		ID:   "4fddf9a4-fbd1-4083-98aa-e4d0e584e7bb",
+	}
+
+	predicate, args := keysetAfter(keys, cursor, 2)
+
+	require.Equal(t, "(created_at < $2::timestamp)"+
+		" OR (created_at = $2::timestamp AND first_name > $3::text)"+
+		" OR (created_at = $2::timestamp AND first_name = $3::text AND id > $4::uuid)", predicate)
+	require.Equal(t, []any{"2022-11-05T22:28:36.679554Z", "John", "4fddf9a4-fbd1-4083-98aa-e4d0e584e7bb"}, args)
+}
+
+func TestUserFilter(t *testing.T) {
+	where := userFilter(entities.Filter{
+		Email:          "john@example.com",
+		Name:           "J_o",
+		CreatedBefore:  "2022-11-01T00:00:00Z",
+		IncludeDeleted: "true",
+		Search:         "Doe%",
+	})
+
+	require.Equal(t, "email = $1"+
+		" AND (first_name ILIKE $2 OR last_name ILIKE $2)"+
+		" AND created_at < $3::timestamptz"+
+		" AND (search_vector @@ plainto_tsquery('simple', $4) OR search_text LIKE $5)", where.String())
+	require.Equal(t, []any{"john@example.com", `J\_o%`, "2022-11-01T00:00:00Z", "Doe%", `%doe\%%`}, where.args)
+	require.Equal(t, "deleted_at IS NULL", userFilter(entities.Filter{}).String())
+}
@@ -0,0 +1,120 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/lib/pq"
+)
+
+// ListRoles retrieves all roles along with their permissions.
+func (r Repo) ListRoles(ctx context.Context) ([]entities.Role, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			SELECT name, description,
+				ARRAY(SELECT permission FROM "role_permission" WHERE role = "role".name ORDER BY permission)
+			FROM "role"
+			ORDER BY name;
+			`
+
+	rows, err := r.DB.QueryContext(ctx, SQL)
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while executing query: %w", domainError(err))
+	}
+
+	defer rows.Close()
+
+	var roles []entities.Role
+
+	for rows.Next() {
+		var role entities.Role
+
+		if err := rows.Scan(&role.Name, &role.Description, pq.Array(&role.Permissions)); err != nil {
+			return nil, fmt.Errorf("error occurred while scaning object from query: %w", err)
+		}
+
+		roles = append(roles, role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration: %w", domainError(err))
+	}
+
+	return roles, nil
+}
+
+// FindPermissions retrieves distinct permissions granted by given roles.
+func (r Repo) FindPermissions(ctx context.Context, roles []string) ([]string, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			SELECT DISTINCT permission
+			FROM "role_permission"
+			WHERE role = ANY($1)
+			ORDER BY permission;
+			`
+
+	rows, err := r.DB.QueryContext(ctx, SQL, pq.Array(roles))
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while executing query: %w", domainError(err))
+	}
+
+	defer rows.Close()
+
+	var permissions []string
+
+	for rows.Next() {
+		var permission string
+
+		if err := rows.Scan(&permission); err != nil {
+			return nil, fmt.Errorf("error occurred while scaning object from query: %w", err)
+		}
+
+		permissions = append(permissions, permission)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration: %w", domainError(err))
+	}
+
+	return permissions, nil
+}
+
+// AssignRole grants role to the user. Assigning already granted role is a no-op.
+func (r Repo) AssignRole(ctx context.Context, userID, role string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			INSERT INTO "user_role" (user_id, role)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING;
+			`
+
+	if _, err := r.DB.ExecContext(ctx, SQL, userID, role); err != nil {
+		return fmt.Errorf("error inserting into database: %w", domainError(err))
+	}
+
+	return nil
+}
+
+// RevokeRole takes role away from the user. Revoking role that is not granted is a no-op.
+func (r Repo) RevokeRole(ctx context.Context, userID, role string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			DELETE FROM "user_role"
+			WHERE user_id = $1 AND role = $2;
+			`
+
+	if _, err := r.DB.ExecContext(ctx, SQL, userID, role); err != nil {
+		return fmt.Errorf("error deleting from database: %w", domainError(err))
+	}
+
+	return nil
+}
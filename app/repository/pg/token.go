@@ -0,0 +1,115 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+)
+
+// AddRefreshToken stores hash of issued refresh token.
+func (r Repo) AddRefreshToken(ctx context.Context, token entities.RefreshToken) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			INSERT INTO "refresh_token" (user_id, token_hash, expires_at)
+			VALUES ($1, $2, $3);
+			`
+
+	if _, err := r.DB.ExecContext(ctx, SQL, token.UserID, token.TokenHash, token.ExpiresAt); err != nil {
+		return fmt.Errorf("error inserting into database: %w", domainError(err))
+	}
+
+	return nil
+}
+
+// FindRefreshToken finds refresh token by its hash regardless of its state.
+func (r Repo) FindRefreshToken(ctx context.Context, tokenHash string) (*entities.RefreshToken, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var token entities.RefreshToken
+
+	const SQL = `
+			SELECT id, user_id, token_hash, expires_at, revoked_at
+			FROM "refresh_token"
+			WHERE token_hash = $1;
+			`
+
+	if err := r.DB.QueryRowContext(ctx, SQL, tokenHash).Scan(&token.ID, &token.UserID, &token.TokenHash, &token.ExpiresAt,
+		&token.RevokedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entities.ErrTokenNotFound
+		}
+
+		return nil, fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	return &token, nil
+}
+
+// RevokeRefreshToken atomically revokes active (not revoked and not expired)
+// refresh token and returns it, so the same token can not be used twice.
+func (r Repo) RevokeRefreshToken(ctx context.Context, tokenHash string) (*entities.RefreshToken, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var token entities.RefreshToken
+
+	const SQL = `
+			UPDATE "refresh_token"
+			SET revoked_at = NOW()
+			WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW()
+			RETURNING id, user_id, token_hash, expires_at, revoked_at;
+			`
+
+	if err := r.DB.QueryRowContext(ctx, SQL, tokenHash).Scan(&token.ID, &token.UserID, &token.TokenHash, &token.ExpiresAt,
+		&token.RevokedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entities.ErrTokenNotFound
+		}
+
+		return nil, fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	return &token, nil
+}
+
+// DeleteRefreshToken deletes refresh token whether it is active or not.
+// Deleting unknown token is not an error.
+func (r Repo) DeleteRefreshToken(ctx context.Context, tokenHash string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			DELETE FROM "refresh_token"
+			WHERE token_hash = $1;
+			`
+
+	if _, err := r.DB.ExecContext(ctx, SQL, tokenHash); err != nil {
+		return fmt.Errorf("error deleting from database: %w", domainError(err))
+	}
+
+	return nil
+}
+
+// DeleteUserRefreshTokens deletes all refresh tokens of the user,
+// including revoked ones, so none of them is recognised anymore.
+func (r Repo) DeleteUserRefreshTokens(ctx context.Context, userID string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			DELETE FROM "refresh_token"
+			WHERE user_id = $1;
+			`
+
+	if _, err := r.DB.ExecContext(ctx, SQL, userID); err != nil {
+		return fmt.Errorf("error deleting from database: %w", domainError(err))
+	}
+
+	return nil
+}
@@ -4,114 +4,441 @@
 package pg
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/go-devs-ua/octagon/app/entities"
-	"github.com/go-devs-ua/octagon/app/globals"
-	"github.com/go-devs-ua/octagon/pkg/hash"
 	"github.com/lib/pq"
 	_ "github.com/lib/pq" // Standard blanc import for pq.
 )
 
-const ErrCodeUniqueViolation = "unique_violation"
-
 // Repo wraps a database handle.
+// Every query is cancelled after QueryTimeout
+// unless context of the call is done earlier.
+// Zero QueryTimeout leaves queries bound by the context only.
 type Repo struct {
-	DB *sql.DB
+	DB           *sql.DB
+	QueryTimeout time.Duration
 }
 
 // NewRepo will initialise new instance of Repo.
-func NewRepo(db *sql.DB) *Repo {
+func NewRepo(db *sql.DB, queryTimeout time.Duration) *Repo {
 	return &Repo{
-		DB: db,
+		DB:           db,
+		QueryTimeout: queryTimeout,
 	}
 }
 
-// AddUser method implements storing the user in the database.
-func (r Repo) AddUser(user entities.User) (string, error) {
+// withTimeout bounds ctx by query timeout.
+func (r Repo) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, r.QueryTimeout)
+}
+
+// AddUser method implements storing the user in the database
+// along with assigning default member role.
+// Password is expected to be already hashed.
+func (r Repo) AddUser(ctx context.Context, user entities.User) (string, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	var id string
 
 	const SQL = `
-			INSERT INTO "user" (first_name, last_name, email, password)
-			VALUES ($1, $2, $3, $4) 
-			RETURNING id;
+			WITH new_user AS (
+				INSERT INTO "user" (first_name, last_name, email, password)
+				VALUES ($1, $2, $3, $4) 
+				RETURNING id
+			)
+			INSERT INTO "user_role" (user_id, role)
+			SELECT id, $5 FROM new_user
+			RETURNING user_id;
 			`
 
-	if err := r.DB.QueryRow(SQL, user.FirstName, user.LastName, user.Email, hash.SHA256(user.Password)).Scan(&id); err != nil {
-		pqErr := new(pq.Error)
-		if errors.As(err, &pqErr) && pqErr.Code.Name() == ErrCodeUniqueViolation {
-			return "", globals.ErrDuplicateEmail
-		}
-
-		return "", fmt.Errorf("error inserting into database: %w", err)
+	if err := r.DB.QueryRowContext(ctx, SQL, user.FirstName, user.LastName, user.Email, user.Password,
+		entities.RoleMember).Scan(&id); err != nil {
+		return "", fmt.Errorf("error inserting into database: %w", domainError(err))
 	}
 
 	return id, nil
 }
 
 // FindUser method implements logic of finding user in the database by ID.
-func (r Repo) FindUser(id string) (*entities.User, error) {
-	var user entities.User
+func (r Repo) FindUser(ctx context.Context, id string) (*entities.User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var (
+		user       entities.User
+		verifiedAt sql.NullString
+	)
 
 	const SQL = `
-			SELECT id, first_name, last_name, email, created_at 
+			SELECT id, first_name, COALESCE(last_name, ''), email, created_at, updated_at, verified_at,
+				ARRAY(SELECT role FROM "user_role" WHERE user_id = "user".id ORDER BY role)
 			FROM "user" 
 			WHERE id = $1
 			AND deleted_at is null;
 			`
 
-	if err := r.DB.QueryRow(SQL, id).Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.CreatedAt); err != nil {
+	if err := r.DB.QueryRowContext(ctx, SQL, id).Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.CreatedAt,
+		&user.UpdatedAt, &verifiedAt, pq.Array(&user.Roles)); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, globals.ErrNotFound
+			return nil, entities.ErrNotFound
 		}
 
-		return nil, fmt.Errorf("internal error while scanning row: %w", err)
+		return nil, fmt.Errorf("internal error while scanning row: %w", domainError(err))
 	}
 
+	user.VerifiedAt = verifiedAt.String
+
+	return &user, nil
+}
+
+// FindUserByEmail method implements logic of finding user in the database by email.
+// Unlike FindUser it also returns password hash, so it can be used for authentication.
+func (r Repo) FindUserByEmail(ctx context.Context, email string) (*entities.User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var (
+		user       entities.User
+		verifiedAt sql.NullString
+	)
+
+	const SQL = `
+			SELECT id, first_name, COALESCE(last_name, ''), email, password, created_at, verified_at,
+				ARRAY(SELECT role FROM "user_role" WHERE user_id = "user".id ORDER BY role)
+			FROM "user" 
+			WHERE email = $1
+			AND deleted_at is null;
+			`
+
+	if err := r.DB.QueryRowContext(ctx, SQL, email).Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Password,
+		&user.CreatedAt, &verifiedAt, pq.Array(&user.Roles)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entities.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	user.VerifiedAt = verifiedAt.String
+
 	return &user, nil
 }
 
 // GetAllUsers retrieves list of users from database.
-func (r Repo) GetAllUsers(params entities.QueryParams) ([]entities.User, error) {
+func (r Repo) GetAllUsers(ctx context.Context, params entities.QueryParams) ([]entities.User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	keys, err := entities.ParseSort(params.Sort)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing sort: %w", err)
+	}
+
+	if params.UsesCursor() {
+		return r.getUsersAfter(ctx, params, keys)
+	}
+
+	const SQL = `
+			SELECT id, email, first_name, COALESCE(last_name, ''), created_at, updated_at, verified_at, deleted_at,
+				ARRAY(SELECT role FROM "user_role" WHERE user_id = "user".id ORDER BY role)
+			FROM "user" 
+			WHERE %s
+			ORDER BY %s
+			LIMIT  %s
+			OFFSET %s;
+	`
+
+	where := userFilter(params.Filter)
+	limit, offset := len(where.args)+1, len(where.args)+2
+	query := fmt.Sprintf(SQL, where, orderBy(keys), fmt.Sprintf("$%d", limit), fmt.Sprintf("$%d", offset))
+
+	rows, err := r.DB.QueryContext(ctx, query, append(where.args, params.Limit, params.Offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while executing query: %w", domainError(err))
+	}
+
+	return scanUsers(rows)
+}
+
+// CountUsers counts users matching the filter.
+func (r Repo) CountUsers(ctx context.Context, filter entities.Filter) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	const SQL = `
-			SELECT id, email, first_name, last_name, created_at
+			SELECT COUNT(*) 
 			FROM "user" 
-			WHERE deleted_at IS NULL
-			ORDER BY CASE WHEN $1 = '' THEN 'first_name, last_name' ELSE $1 END
-			LIMIT  $2
-			OFFSET $3;
+			WHERE %s;
 	`
 
-	rows, err := r.DB.Query(SQL, params.Sort, params.Limit, params.Offset)
+	var n int64
+
+	where := userFilter(filter)
+	if err := r.DB.QueryRowContext(ctx, fmt.Sprintf(SQL, where), where.args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	return n, nil
+}
+
+// getUsersAfter retrieves page of users which starts right after the cursor,
+// so rows inserted in the middle of the scan are neither skipped nor repeated.
+func (r Repo) getUsersAfter(ctx context.Context, params entities.QueryParams, keys []entities.SortKey) ([]entities.User, error) {
+	const SQL = `
+			SELECT id, email, first_name, COALESCE(last_name, ''), created_at, updated_at, verified_at, deleted_at,
+				ARRAY(SELECT role FROM "user_role" WHERE user_id = "user".id ORDER BY role)
+			FROM "user" 
+			WHERE %s
+			AND (%s)
+			ORDER BY %s
+			LIMIT %s;
+	`
+
+	where := userFilter(params.Filter)
+	args := append(where.args, params.Limit)
+	limit := fmt.Sprintf("$%d", len(args))
+	predicate := "TRUE"
+
+	if params.Cursor != "" {
+		cursor, err := entities.DecodeCursor(params.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding cursor: %w", err)
+		}
+
+		if len(cursor.Keys) != len(keys) {
+			return nil, entities.ErrMalformedCursor
+		}
+
+		var after []any
+
+		predicate, after = keysetAfter(keys, cursor, len(args)+1)
+		args = append(args, after...)
+	}
+
+	rows, err := r.DB.QueryContext(ctx, fmt.Sprintf(SQL, where, predicate, orderBy(keys), limit), args...)
 	if err != nil {
-		return nil, fmt.Errorf("error occurred while executing query: %w", err)
+		return nil, fmt.Errorf("error occurred while executing query: %w", domainError(err))
 	}
 
+	return scanUsers(rows)
+}
+
+func scanUsers(rows *sql.Rows) ([]entities.User, error) {
 	defer rows.Close()
 
 	var users []entities.User
 
 	for rows.Next() {
-		var user entities.User
+		var (
+			user                  entities.User
+			verifiedAt, deletedAt sql.NullString
+		)
 
-		if err := rows.Scan(&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.CreatedAt); err != nil {
+		if err := rows.Scan(&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt,
+			&verifiedAt, &deletedAt, pq.Array(&user.Roles)); err != nil {
 			return nil, fmt.Errorf("error occurred while scaning object from query: %w", err)
 		}
 
+		user.VerifiedAt, user.DeletedAt = verifiedAt.String, deletedAt.String
+
 		users = append(users, user)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error occurred during iteration: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration: %w", domainError(err))
 	}
 
 	return users, nil
 }
 
+// UpdateUser applies patch to the user and returns updated user.
+// Changing email resets its verification.
+func (r Repo) UpdateUser(ctx context.Context, id string, patch entities.UserPatch) (*entities.User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var (
+		user       entities.User
+		verifiedAt sql.NullString
+	)
+
+	const SQL = `
+			UPDATE "user" 
+			SET first_name = COALESCE($2, first_name),
+				last_name = COALESCE($3, last_name),
+				email = COALESCE($4, email),
+				verified_at = CASE WHEN COALESCE($4, email) = email THEN verified_at END
+			WHERE id = $1 AND deleted_at IS NULL 
+			RETURNING id, first_name, COALESCE(last_name, ''), email, created_at, updated_at, verified_at,
+				ARRAY(SELECT role FROM "user_role" WHERE user_id = "user".id ORDER BY role);
+			`
+
+	if err := r.DB.QueryRowContext(ctx, SQL, id, patch.FirstName, patch.LastName, patch.Email).Scan(&user.ID, &user.FirstName,
+		&user.LastName, &user.Email, &user.CreatedAt, &user.UpdatedAt, &verifiedAt, pq.Array(&user.Roles)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entities.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	user.VerifiedAt = verifiedAt.String
+
+	return &user, nil
+}
+
+// MarkVerified records that user confirmed ownership of the email.
+// Verifying already verified user keeps original verification time.
+func (r Repo) MarkVerified(ctx context.Context, id string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			UPDATE "user" 
+			SET verified_at = COALESCE(verified_at, NOW()) 
+			WHERE id = $1 AND deleted_at IS NULL 
+			RETURNING id;
+			`
+
+	if err := r.DB.QueryRowContext(ctx, SQL, id).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.ErrNotFound
+		}
+
+		return fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	return nil
+}
+
+// RestoreUser undoes deletion of the user deleted after given time
+// and returns restored user.
+func (r Repo) RestoreUser(ctx context.Context, id string, deletedAfter time.Time) (*entities.User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var (
+		user       entities.User
+		verifiedAt sql.NullString
+	)
+
+	const SQL = `
+			UPDATE "user" 
+			SET deleted_at = NULL 
+			WHERE id = $1 AND deleted_at > $2 AND anonymized_at IS NULL 
+			RETURNING id, first_name, COALESCE(last_name, ''), email, created_at, updated_at, verified_at,
+				ARRAY(SELECT role FROM "user_role" WHERE user_id = "user".id ORDER BY role);
+			`
+
+	if err := r.DB.QueryRowContext(ctx, SQL, id, deletedAfter).Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email,
+		&user.CreatedAt, &user.UpdatedAt, &verifiedAt, pq.Array(&user.Roles)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entities.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	user.VerifiedAt = verifiedAt.String
+
+	return &user, nil
+}
+
+// PurgeUsers removes users deleted before given time
+// along with all their data and returns number of removed users.
+func (r Repo) PurgeUsers(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			DELETE FROM "user" 
+			WHERE deleted_at < $1;
+			`
+
+	res, err := r.DB.ExecContext(ctx, SQL, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting from database: %w", domainError(err))
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error counting deleted rows: %w", err)
+	}
+
+	return n, nil
+}
+
+// AnonymizeUsers wipes personal data and credentials of users deleted before given time,
+// keeping rows themselves, and returns number of anonymised users.
+func (r Repo) AnonymizeUsers(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			WITH anonymized AS (
+				UPDATE "user" 
+				SET first_name = 'Deleted',
+					last_name = NULL,
+					email = 'deleted-' || id || '@invalid',
+					password = '',
+					anonymized_at = NOW()
+				WHERE deleted_at < $1 AND anonymized_at IS NULL 
+				RETURNING id
+			), revoked AS (
+				DELETE FROM "refresh_token" WHERE user_id IN (SELECT id FROM anonymized)
+			), used AS (
+				DELETE FROM "user_token" WHERE user_id IN (SELECT id FROM anonymized)
+			)
+			SELECT COUNT(*) FROM anonymized;
+			`
+
+	var n int64
+
+	if err := r.DB.QueryRowContext(ctx, SQL, deletedBefore).Scan(&n); err != nil {
+		return 0, fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	return n, nil
+}
+
+// UpdatePassword replaces password hash of the user.
+func (r Repo) UpdatePassword(ctx context.Context, user entities.User) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			UPDATE "user" 
+			SET password = $2 
+			WHERE id = $1 AND deleted_at IS NULL 
+			RETURNING id;
+			`
+
+	if err := r.DB.QueryRowContext(ctx, SQL, user.ID, user.Password).Scan(&user.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.ErrNotFound
+		}
+
+		return fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	return nil
+}
+
 // DeleteUser removes user from database.
-func (r Repo) DeleteUser(user entities.User) error {
+func (r Repo) DeleteUser(ctx context.Context, user entities.User) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	const SQL = `
 			UPDATE "user" 
 			SET deleted_at = NOW() 
@@ -119,12 +446,12 @@ func (r Repo) DeleteUser(user entities.User) error {
 			RETURNING id;
 			`
 
-	if err := r.DB.QueryRow(SQL, user.ID).Scan(&user.ID); err != nil {
+	if err := r.DB.QueryRowContext(ctx, SQL, user.ID).Scan(&user.ID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return globals.ErrNotFound
+			return entities.ErrNotFound
 		}
 
-		return fmt.Errorf("internal error while scanning row: %w", err)
+		return fmt.Errorf("internal error while scanning row: %w", domainError(err))
 	}
 
 	return nil
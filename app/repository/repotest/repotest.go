@@ -0,0 +1,472 @@
+// Package repotest holds on contract tests every repository
+// implementation has to pass, so backends are interchangeable.
+package repotest
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/go-devs-ua/octagon/app/usecase"
+	"github.com/stretchr/testify/require"
+)
+
+// Repository combines all repositories the app depends on.
+type Repository interface {
+	usecase.Repository
+	usecase.AuthRepository
+}
+
+// Run runs contract tests against repositories created by newRepo.
+// Each call of newRepo has to return an empty repository.
+func Run(t *testing.T, newRepo func(t *testing.T) Repository) {
+	t.Helper()
+
+	tests := map[string]func(t *testing.T, repo Repository){
+		"add and find user":         testAddFindUser,
+		"update user":               testUpdateUser,
+		"delete and restore user":   testDeleteRestoreUser,
+		"purge and anonymize users": testPurgeUsers,
+		"list users by offset":      testListByOffset,
+		"list users by cursor":      testListByCursor,
+		"filter users":              testFilterUsers,
+		"refresh tokens":            testRefreshTokens,
+		"roles":                     testRoles,
+		"user tokens":               testUserTokens,
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			test(t, newRepo(t))
+		})
+	}
+}
+
+func addUser(t *testing.T, repo Repository, firstName, lastName, email string) string {
+	t.Helper()
+
+	ctx := context.Background()
+
+	id, err := repo.AddUser(ctx, entities.User{FirstName: firstName, LastName: lastName, Email: email, Password: "hash"})
+	require.NoError(t, err)
+
+	return id
+}
+
+func testAddFindUser(t *testing.T, repo Repository) {
+	ctx := context.Background()
+
+	id := addUser(t, repo, "John", "Doe", "john@example.com")
+
+	user, err := repo.FindUser(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, "John", user.FirstName)
+	require.Equal(t, "Doe", user.LastName)
+	require.Equal(t, "john@example.com", user.Email)
+	require.Empty(t, user.Password)
+	require.NotEmpty(t, user.CreatedAt)
+	require.Equal(t, []string{entities.RoleMember}, user.Roles)
+	require.False(t, user.IsVerified())
+
+	user, err = repo.FindUserByEmail(ctx, "john@example.com")
+	require.NoError(t, err)
+	require.Equal(t, id, user.ID)
+	require.Equal(t, "hash", user.Password)
+
+	_, err = repo.AddUser(ctx, entities.User{FirstName: "Jack", Email: "john@example.com", Password: "hash"})
+	require.ErrorIs(t, err, entities.ErrDuplicateEmail)
+
+	_, err = repo.FindUser(ctx, "4fddf9a4-fbd1-4083-98aa-e4d0e584e7bb")
+	require.ErrorIs(t, err, entities.ErrNotFound)
+
+	_, err = repo.FindUserByEmail(ctx, "jack@example.com")
+	require.ErrorIs(t, err, entities.ErrNotFound)
+}
+
+func testUpdateUser(t *testing.T, repo Repository) {
+	ctx := context.Background()
+
+	id := addUser(t, repo, "John", "Doe", "john@example.com")
+	addUser(t, repo, "Jane", "Doe", "jane@example.com")
+
+	require.NoError(t, repo.MarkVerified(ctx, id))
+
+	name := "Johnny"
+	user, err := repo.UpdateUser(ctx, id, entities.UserPatch{FirstName: &name})
+	require.NoError(t, err)
+	require.Equal(t, "Johnny", user.FirstName)
+	require.True(t, user.IsVerified())
+
+	email := "jane@example.com"
+	_, err = repo.UpdateUser(ctx, id, entities.UserPatch{Email: &email})
+	require.ErrorIs(t, err, entities.ErrDuplicateEmail)
+
+	email = "johnny@example.com"
+	user, err = repo.UpdateUser(ctx, id, entities.UserPatch{Email: &email})
+	require.NoError(t, err)
+	require.Equal(t, email, user.Email)
+	require.False(t, user.IsVerified())
+
+	require.NoError(t, repo.UpdatePassword(ctx, entities.User{ID: id, Password: "new hash"}))
+
+	user, err = repo.FindUserByEmail(ctx, email)
+	require.NoError(t, err)
+	require.Equal(t, "new hash", user.Password)
+
+	_, err = repo.UpdateUser(ctx, "4fddf9a4-fbd1-4083-98aa-e4d0e584e7bb", entities.UserPatch{FirstName: &name})
+	require.ErrorIs(t, err, entities.ErrNotFound)
+	require.ErrorIs(t, repo.MarkVerified(ctx, "4fddf9a4-fbd1-4083-98aa-e4d0e584e7bb"), entities.ErrNotFound)
+}
+
+func testDeleteRestoreUser(t *testing.T, repo Repository) {
+	ctx := context.Background()
+
+	before := time.Now().Add(-time.Hour)
+	id := addUser(t, repo, "John", "Doe", "john@example.com")
+
+	require.NoError(t, repo.DeleteUser(ctx, entities.User{ID: id}))
+	require.ErrorIs(t, repo.DeleteUser(ctx, entities.User{ID: id}), entities.ErrNotFound)
+
+	_, err := repo.FindUser(ctx, id)
+	require.ErrorIs(t, err, entities.ErrNotFound)
+
+	_, err = repo.RestoreUser(ctx, id, time.Now().Add(time.Hour))
+	require.ErrorIs(t, err, entities.ErrNotFound)
+
+	user, err := repo.RestoreUser(ctx, id, before)
+	require.NoError(t, err)
+	require.Equal(t, id, user.ID)
+
+	_, err = repo.RestoreUser(ctx, id, before)
+	require.ErrorIs(t, err, entities.ErrNotFound)
+
+	require.NoError(t, repo.DeleteUser(ctx, entities.User{ID: id}))
+	addUser(t, repo, "Jack", "Doe", "john@example.com")
+
+	_, err = repo.RestoreUser(ctx, id, before)
+	require.ErrorIs(t, err, entities.ErrDuplicateEmail)
+}
+
+func testPurgeUsers(t *testing.T, repo Repository) {
+	ctx := context.Background()
+
+	purged := addUser(t, repo, "John", "Doe", "john@example.com")
+	anonymized := addUser(t, repo, "Jane", "Doe", "jane@example.com")
+	kept := addUser(t, repo, "Jack", "Doe", "jack@example.com")
+
+	require.NoError(t, repo.DeleteUser(ctx, entities.User{ID: purged}))
+
+	n, err := repo.PurgeUsers(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	n, err = repo.PurgeUsers(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	_, err = repo.RestoreUser(ctx, purged, time.Time{})
+	require.ErrorIs(t, err, entities.ErrNotFound)
+
+	require.NoError(t, repo.DeleteUser(ctx, entities.User{ID: anonymized}))
+
+	n, err = repo.AnonymizeUsers(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	_, err = repo.RestoreUser(ctx, anonymized, time.Time{})
+	require.ErrorIs(t, err, entities.ErrNotFound)
+
+	_, err = repo.FindUser(ctx, kept)
+	require.NoError(t, err)
+
+	total, err := repo.CountUsers(ctx, entities.Filter{IncludeDeleted: "true"})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), total)
+
+	users, err := repo.GetAllUsers(ctx, entities.QueryParams{
+		Offset: "0",
+		Limit:  "10",
+		Sort:   "first_name",
+		Filter: entities.Filter{IncludeDeleted: "true"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Deleted", "Jack"}, firstNames(users))
+	require.Empty(t, users[0].LastName)
+	require.Equal(t, anonymized, users[0].ID)
+}
+
+// names are added in random order and consist of ASCII letters only,
+// so every collation sorts them the same way.
+var names = []string{"Dave", "Alice", "Eve", "Carol", "Bob"}
+
+func addNamedUsers(t *testing.T, repo Repository) {
+	t.Helper()
+
+	for i, name := range names {
+		addUser(t, repo, name, "Doe", "user"+strconv.Itoa(i)+"@example.com")
+	}
+}
+
+func firstNames(users []entities.User) []string {
+	result := make([]string, 0, len(users))
+
+	for _, user := range users {
+		result = append(result, user.FirstName)
+	}
+
+	return result
+}
+
+func testListByOffset(t *testing.T, repo Repository) {
+	ctx := context.Background()
+
+	addNamedUsers(t, repo)
+
+	users, err := repo.GetAllUsers(ctx, entities.QueryParams{Offset: "1", Limit: "2", Sort: "first_name"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Bob", "Carol"}, firstNames(users))
+
+	users, err = repo.GetAllUsers(ctx, entities.QueryParams{Offset: "0", Limit: "10", Sort: "-first_name"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Eve", "Dave", "Carol", "Bob", "Alice"}, firstNames(users))
+
+	users, err = repo.GetAllUsers(ctx, entities.QueryParams{Offset: "5", Limit: "10", Sort: "first_name"})
+	require.NoError(t, err)
+	require.Empty(t, users)
+}
+
+func testListByCursor(t *testing.T, repo Repository) {
+	ctx := context.Background()
+
+	addNamedUsers(t, repo)
+
+	var (
+		params = entities.QueryParams{Limit: "2", Sort: "last_name,-first_name"}
+		pages  [][]string
+	)
+
+	for {
+		users, err := repo.GetAllUsers(ctx, params)
+		require.NoError(t, err)
+
+		if len(users) > 0 {
+			pages = append(pages, firstNames(users))
+		}
+
+		params.Cursor = params.NextCursor(users)
+		if params.Cursor == "" {
+			break
+		}
+	}
+
+	require.Equal(t, [][]string{{"Eve", "Dave"}, {"Carol", "Bob"}, {"Alice"}}, pages)
+
+	params = entities.QueryParams{Limit: "3", Sort: "created_at"}
+
+	users, err := repo.GetAllUsers(ctx, params)
+	require.NoError(t, err)
+	require.Len(t, users, 3)
+
+	params.Cursor = params.NextCursor(users)
+
+	rest, err := repo.GetAllUsers(ctx, params)
+	require.NoError(t, err)
+	require.Len(t, rest, 2)
+	require.ElementsMatch(t, names, append(firstNames(users), firstNames(rest)...))
+}
+
+func testFilterUsers(t *testing.T, repo Repository) {
+	ctx := context.Background()
+
+	addNamedUsers(t, repo)
+
+	deleted := addUser(t, repo, "Mallory", "Smith", "mallory@example.com")
+	require.NoError(t, repo.DeleteUser(ctx, entities.User{ID: deleted}))
+
+	tests := map[string]struct {
+		filter   entities.Filter
+		expected []string
+	}{
+		"no filter": {
+			expected: []string{"Alice", "Bob", "Carol", "Dave", "Eve"},
+		},
+		"email": {
+			filter:   entities.Filter{Email: "user0@example.com"},
+			expected: []string{"Dave"},
+		},
+		"name prefix": {
+			filter:   entities.Filter{Name: "ca"},
+			expected: []string{"Carol"},
+		},
+		"last name prefix": {
+			filter:   entities.Filter{Name: "do"},
+			expected: []string{"Alice", "Bob", "Carol", "Dave", "Eve"},
+		},
+		"created before": {
+			filter: entities.Filter{CreatedBefore: "2000-01-01T00:00:00Z"},
+		},
+		"created after": {
+			filter:   entities.Filter{CreatedAfter: "2000-01-01T00:00:00Z", Name: "e"},
+			expected: []string{"Eve"},
+		},
+		"include deleted": {
+			filter:   entities.Filter{IncludeDeleted: "true", Name: "m"},
+			expected: []string{"Mallory"},
+		},
+		"search": {
+			filter:   entities.Filter{Search: "bob doe"},
+			expected: []string{"Bob"},
+		},
+		"search substring": {
+			filter:   entities.Filter{Search: "ER0@EXAMPLE"},
+			expected: []string{"Dave"},
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			users, err := repo.GetAllUsers(ctx, entities.QueryParams{Offset: "0", Limit: "10", Sort: "first_name",
+				Filter: tc.filter})
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, firstNamesOrNil(users))
+
+			total, err := repo.CountUsers(ctx, tc.filter)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(tc.expected)), total)
+		})
+	}
+}
+
+func firstNamesOrNil(users []entities.User) []string {
+	if len(users) == 0 {
+		return nil
+	}
+
+	return firstNames(users)
+}
+
+func testRefreshTokens(t *testing.T, repo Repository) {
+	ctx := context.Background()
+
+	id := addUser(t, repo, "John", "Doe", "john@example.com")
+
+	require.NoError(t, repo.AddRefreshToken(ctx, entities.RefreshToken{UserID: id, TokenHash: "active",
+		ExpiresAt: time.Now().Add(time.Hour)}))
+	require.NoError(t, repo.AddRefreshToken(ctx, entities.RefreshToken{UserID: id, TokenHash: "expired",
+		ExpiresAt: time.Now().Add(-time.Hour)}))
+	require.NoError(t, repo.AddRefreshToken(ctx, entities.RefreshToken{UserID: id, TokenHash: "other",
+		ExpiresAt: time.Now().Add(time.Hour)}))
+
+	token, err := repo.FindRefreshToken(ctx, "expired")
+	require.NoError(t, err)
+	require.Equal(t, id, token.UserID)
+	require.Nil(t, token.RevokedAt)
+
+	_, err = repo.RevokeRefreshToken(ctx, "expired")
+	require.ErrorIs(t, err, entities.ErrTokenNotFound)
+
+	token, err = repo.RevokeRefreshToken(ctx, "active")
+	require.NoError(t, err)
+	require.NotNil(t, token.RevokedAt)
+
+	_, err = repo.RevokeRefreshToken(ctx, "active")
+	require.ErrorIs(t, err, entities.ErrTokenNotFound)
+
+	require.NoError(t, repo.DeleteRefreshToken(ctx, "expired"))
+	require.NoError(t, repo.DeleteRefreshToken(ctx, "expired"), "deleting unknown token is not an error")
+
+	_, err = repo.FindRefreshToken(ctx, "expired")
+	require.ErrorIs(t, err, entities.ErrTokenNotFound)
+
+	require.NoError(t, repo.DeleteUserRefreshTokens(ctx, id))
+
+	_, err = repo.FindRefreshToken(ctx, "active")
+	require.ErrorIs(t, err, entities.ErrTokenNotFound, "revoked tokens are deleted too")
+
+	_, err = repo.RevokeRefreshToken(ctx, "other")
+	require.ErrorIs(t, err, entities.ErrTokenNotFound)
+
+	_, err = repo.FindRefreshToken(ctx, "unknown")
+	require.ErrorIs(t, err, entities.ErrTokenNotFound)
+}
+
+func testRoles(t *testing.T, repo Repository) {
+	ctx := context.Background()
+
+	id := addUser(t, repo, "John", "Doe", "john@example.com")
+
+	roles, err := repo.ListRoles(ctx)
+	require.NoError(t, err)
+	require.Len(t, roles, 2)
+	require.Equal(t, entities.RoleAdmin, roles[0].Name)
+	require.Equal(t, []string{entities.PermRolesManage, entities.PermUsersList, entities.PermUsersManage},
+		roles[0].Permissions)
+	require.Equal(t, entities.RoleMember, roles[1].Name)
+
+	require.NoError(t, repo.AssignRole(ctx, id, entities.RoleAdmin))
+	require.NoError(t, repo.AssignRole(ctx, id, entities.RoleAdmin))
+	require.ErrorIs(t, repo.AssignRole(ctx, id, "owner"), entities.ErrRoleNotFound)
+	require.ErrorIs(t, repo.AssignRole(ctx, "4fddf9a4-fbd1-4083-98aa-e4d0e584e7bb", entities.RoleAdmin),
+		entities.ErrNotFound)
+
+	user, err := repo.FindUser(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, []string{entities.RoleAdmin, entities.RoleMember}, user.Roles)
+
+	permissions, err := repo.FindPermissions(ctx, user.Roles)
+	require.NoError(t, err)
+	require.Equal(t, []string{entities.PermRolesManage, entities.PermUsersList, entities.PermUsersManage},
+		permissions)
+
+	require.NoError(t, repo.RevokeRole(ctx, id, entities.RoleAdmin))
+	require.NoError(t, repo.RevokeRole(ctx, id, entities.RoleAdmin))
+
+	user, err = repo.FindUser(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, []string{entities.RoleMember}, user.Roles)
+
+	permissions, err = repo.FindPermissions(ctx, user.Roles)
+	require.NoError(t, err)
+	require.Empty(t, permissions)
+}
+
+func testUserTokens(t *testing.T, repo Repository) {
+	ctx := context.Background()
+
+	id := addUser(t, repo, "John", "Doe", "john@example.com")
+	purpose := entities.TokenPurposeEmailVerification
+
+	for hash, expiresAt := range map[string]time.Time{
+		"first":   time.Now().Add(time.Hour),
+		"second":  time.Now().Add(time.Hour),
+		"expired": time.Now().Add(-time.Hour),
+	} {
+		require.NoError(t, repo.AddUserToken(ctx, entities.UserToken{UserID: id, Purpose: purpose, TokenHash: hash,
+			ExpiresAt: expiresAt}))
+	}
+
+	_, err := repo.ConsumeUserToken(ctx, "first", entities.TokenPurposePasswordReset)
+	require.ErrorIs(t, err, entities.ErrTokenNotFound)
+
+	_, err = repo.ConsumeUserToken(ctx, "expired", purpose)
+	require.ErrorIs(t, err, entities.ErrTokenNotFound)
+
+	token, err := repo.ConsumeUserToken(ctx, "first", purpose)
+	require.NoError(t, err)
+	require.Equal(t, id, token.UserID)
+	require.Equal(t, purpose, token.Purpose)
+
+	_, err = repo.ConsumeUserToken(ctx, "first", purpose)
+	require.ErrorIs(t, err, entities.ErrTokenNotFound)
+
+	require.NoError(t, repo.RevokeUserTokens(ctx, id, purpose))
+
+	_, err = repo.ConsumeUserToken(ctx, "second", purpose)
+	require.ErrorIs(t, err, entities.ErrTokenNotFound)
+}
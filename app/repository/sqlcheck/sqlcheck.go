@@ -0,0 +1,77 @@
+// Package sqlcheck lives in repository dir and holds on readiness checks
+// of SQL databases, which are shared by postgres and sqlite repositories.
+package sqlcheck
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/go-devs-ua/octagon/app/usecase"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Ping checks that database is reachable.
+func Ping(db *sql.DB) usecase.Check {
+	return func(ctx context.Context) error {
+		if err := db.PingContext(ctx); err != nil {
+			return fmt.Errorf("ping to database failed: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// Migrations checks that all migrations found in source
+// are recorded in gorp_migrations table, i.e. the schema is current.
+func Migrations(db *sql.DB, source migrate.MigrationSource) usecase.Check {
+	return func(ctx context.Context) error {
+		const SQL = `
+			SELECT id 
+			FROM gorp_migrations;
+			`
+
+		migrations, err := source.FindMigrations()
+		if err != nil {
+			return fmt.Errorf("error finding migrations: %w", err)
+		}
+
+		rows, err := db.QueryContext(ctx, SQL)
+		if err != nil {
+			return fmt.Errorf("error occurred while executing query: %w", err)
+		}
+
+		defer rows.Close()
+
+		applied := make(map[string]bool)
+
+		for rows.Next() {
+			var id string
+
+			if err := rows.Scan(&id); err != nil {
+				return fmt.Errorf("error occurred while scaning object from query: %w", err)
+			}
+
+			applied[id] = true
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error occurred during iteration: %w", err)
+		}
+
+		var pending []string
+
+		for _, m := range migrations {
+			if !applied[m.Id] {
+				pending = append(pending, m.Id)
+			}
+		}
+
+		if len(pending) > 0 {
+			return fmt.Errorf("migrations are not applied: %s", strings.Join(pending, ", "))
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,35 @@
+package sqlcheck
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-devs-ua/octagon/app/repository/sqlite"
+	"github.com/go-devs-ua/octagon/cfg"
+	migrate "github.com/rubenv/sql-migrate"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrations(t *testing.T) {
+	db, err := sqlite.ConnectDB(cfg.DB{Path: filepath.Join(t.TempDir(), "octagon.db")})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	source := &migrate.MemoryMigrationSource{Migrations: []*migrate.Migration{
+		{Id: "001_create_table_a.sql", Up: []string{"CREATE TABLE a (id INTEGER);"}},
+		{Id: "002_create_table_b.sql", Up: []string{"CREATE TABLE b (id INTEGER);"}},
+	}}
+
+	require.NoError(t, Ping(db)(ctx))
+
+	_, err = migrate.ExecMax(db, "sqlite3", source, migrate.Up, 1)
+	require.NoError(t, err)
+	require.EqualError(t, Migrations(db, source)(ctx), "migrations are not applied: 002_create_table_b.sql")
+
+	_, err = migrate.Exec(db, "sqlite3", source, migrate.Up)
+	require.NoError(t, err)
+	require.NoError(t, Migrations(db, source)(ctx))
+}
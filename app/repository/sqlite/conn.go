@@ -0,0 +1,27 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	"github.com/go-devs-ua/octagon/cfg"
+	_ "modernc.org/sqlite" // Registers sqlite driver.
+)
+
+// ConnectDB is used to open SQLite database file.
+// Foreign keys are enforced, so deleting user cascades as in postgres.
+func ConnectDB(cfg cfg.DB) (*sql.DB, error) {
+	params := url.Values{"_pragma": {"foreign_keys(1)", "busy_timeout(5000)"}}
+
+	db, err := sql.Open("sqlite", "file:"+cfg.Path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("ping to database failed: %w", err)
+	}
+
+	return db, nil
+}
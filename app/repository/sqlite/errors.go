@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// uniqueUserEmail is how SQLite reports violation of unique user email index.
+// Unlike postgres it names constrained columns instead of constraints.
+const uniqueUserEmail = "UNIQUE constraint failed: user.email"
+
+// domainError translates SQLite error into domain error keeping it as the cause.
+// Extended result code is looked up, so violated kind of constraint is known.
+// Errors which are not reported by SQLite are returned as is.
+func domainError(err error) error {
+	sqliteErr := new(sqlite.Error)
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		if strings.Contains(sqliteErr.Error(), uniqueUserEmail) {
+			return entities.ErrDuplicateEmail.Wrap(err)
+		}
+
+		return entities.ErrConflict.Wrap(err)
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return entities.ErrReferenceNotFound.Wrap(err)
+	case sqlite3.SQLITE_CONSTRAINT_CHECK:
+		return entities.ErrConstraint.Wrap(err)
+	}
+
+	// Busy database is reported with extended codes telling why it is busy.
+	if sqliteErr.Code()&0xff == sqlite3.SQLITE_BUSY {
+		return entities.ErrConcurrentUpdate.Wrap(err)
+	}
+
+	return err
+}
+
+// isConstraintError reports whether err is violation of given kind of constraint,
+// e.g. sqlite3.SQLITE_CONSTRAINT_UNIQUE.
+func isConstraintError(err error, code int) bool {
+	sqliteErr := new(sqlite.Error)
+
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == code
+}
@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDomainError(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, `
+			INSERT INTO "user" (id, first_name, email, password) VALUES ('1', 'John', 'john@example.com', '');
+			INSERT INTO "refresh_token" (id, token_hash, user_id, expires_at) VALUES ('1', 'hash', '1', '2000-01-01T00:00:00.000Z');
+			CREATE TEMP TABLE "positive" (n INTEGER CHECK (n > 0));
+			`)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		SQL    string
+		expErr error
+	}{
+		"duplicate_email": {
+			SQL:    `INSERT INTO "user" (id, first_name, email, password) VALUES ('2', 'John', 'john@example.com', '');`,
+			expErr: entities.ErrDuplicateEmail,
+		},
+		"unique_violation": {
+			SQL:    `INSERT INTO "refresh_token" (id, token_hash, user_id, expires_at) VALUES ('2', 'hash', '1', '2000-01-01T00:00:00.000Z');`,
+			expErr: entities.ErrConflict,
+		},
+		"primary_key_violation": {
+			SQL:    `INSERT INTO "user" (id, first_name, email, password) VALUES ('1', 'Jack', 'jack@example.com', '');`,
+			expErr: entities.ErrConflict,
+		},
+		"foreign_key_violation": {
+			SQL:    `INSERT INTO "user_role" (user_id, role) VALUES ('2', 'member');`,
+			expErr: entities.ErrReferenceNotFound,
+		},
+		"check_violation": {
+			SQL:    `INSERT INTO "positive" (n) VALUES (0);`,
+			expErr: entities.ErrConstraint,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, cause := db.ExecContext(ctx, tt.SQL)
+			require.Error(t, cause)
+
+			err := domainError(cause)
+
+			require.ErrorIs(t, err, tt.expErr)
+			require.ErrorIs(t, err, cause, "cause has to be kept")
+		})
+	}
+
+	t.Run("not_translated", func(t *testing.T) {
+		_, syntaxErr := db.ExecContext(ctx, `SELEC 1;`)
+		require.Error(t, syntaxErr)
+		require.Same(t, syntaxErr, domainError(syntaxErr))
+
+		plainErr := errors.New("driver: bad connection")
+		require.Same(t, plainErr, domainError(plainErr))
+	})
+}
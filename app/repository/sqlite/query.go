@@ -0,0 +1,194 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+)
+
+// sortExpression maps column users can be sorted by onto SQL expression.
+// Only these expressions ever get into ORDER BY, so sort can not inject SQL.
+// Columns are validated by entities.ParseSort, anything else falls back to id.
+func sortExpression(column string) string {
+	switch column {
+	case entities.SortByFirstName:
+		return "first_name"
+	case entities.SortByLastName:
+		return "COALESCE(last_name, '')"
+	case entities.SortByCreatedAt:
+		return "created_at"
+	default:
+		return "id"
+	}
+}
+
+// orderBy turns sort keys into ORDER BY list
+// with id as the last key, so order is stable.
+func orderBy(keys []entities.SortKey) string {
+	exprs := make([]string, 0, len(keys)+1)
+
+	for _, key := range keys {
+		exprs = append(exprs, sortExpression(key.Column)+direction(key.Desc))
+	}
+
+	return strings.Join(append(exprs, "id ASC"), ", ")
+}
+
+// keysetAfter builds predicate matching rows which follow the cursor
+// in order given by keys, and values of its placeholders numbered from first.
+// It is the same predicate pg builds, except that times are compared as stored text,
+// so cursor times are converted to the stored format.
+func keysetAfter(keys []entities.SortKey, cursor entities.Cursor, first int) (string, []any) {
+	var (
+		disjuncts = make([]string, 0, len(keys)+1)
+		equals    = make([]string, 0, len(keys))
+		args      = make([]any, 0, len(keys)+1)
+	)
+
+	for i, key := range keys {
+		expr := sortExpression(key.Column)
+		placeholder := fmt.Sprintf("$%d", first+i)
+
+		disjuncts = append(disjuncts, "("+strings.Join(append(equals, expr+comparison(key.Desc)+placeholder), " AND ")+")")
+		equals = append(equals, expr+" = "+placeholder)
+		args = append(args, cursorValue(key.Column, cursor.Keys[i]))
+	}
+
+	placeholder := fmt.Sprintf("$%d", first+len(keys))
+	disjuncts = append(disjuncts, "("+strings.Join(append(equals, "id > "+placeholder), " AND ")+")")
+	args = append(args, cursor.ID)
+
+	return strings.Join(disjuncts, " OR "), args
+}
+
+func cursorValue(column, value string) string {
+	if column != entities.SortByCreatedAt {
+		return value
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return value
+	}
+
+	return formatTime(t)
+}
+
+// conditions collects SQL conditions joined by AND
+// along with values of their placeholders.
+type conditions struct {
+	exprs []string
+	args  []any
+}
+
+// add appends condition in which %[1]s, %[2]s, etc.
+// stand for placeholders of given values.
+func (c *conditions) add(expr string, args ...any) {
+	placeholders := make([]any, 0, len(args))
+
+	for _, arg := range args {
+		c.args = append(c.args, arg)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(c.args)))
+	}
+
+	c.exprs = append(c.exprs, fmt.Sprintf(expr, placeholders...))
+}
+
+func (c *conditions) String() string {
+	if len(c.exprs) == 0 {
+		return "TRUE"
+	}
+
+	return strings.Join(c.exprs, " AND ")
+}
+
+// userFilter turns filter into conditions.
+// Filter values never get into SQL text, only into placeholders.
+// LIKE of SQLite ignores case of ASCII letters, so it stands for ILIKE.
+func userFilter(f entities.Filter) *conditions {
+	c := new(conditions)
+
+	if !f.WithDeleted() {
+		c.exprs = append(c.exprs, "deleted_at IS NULL")
+	}
+
+	if f.Email != "" {
+		c.add("email = %[1]s", f.Email)
+	}
+
+	if f.Name != "" {
+		c.add(`(first_name LIKE %[1]s ESCAPE '\' OR last_name LIKE %[1]s ESCAPE '\')`, escapeLike(f.Name)+"%")
+	}
+
+	if f.CreatedAfter != "" {
+		c.add("created_at > %[1]s", filterTime(f.CreatedAfter))
+	}
+
+	if f.CreatedBefore != "" {
+		c.add("created_at < %[1]s", filterTime(f.CreatedBefore))
+	}
+
+	if f.Search != "" {
+		expr, args := searchCondition(f.Search)
+		c.add(expr, args...)
+	}
+
+	return c
+}
+
+// searchCondition approximates full-text search of pg without FTS extension:
+// user matches when the text is a part of names and email
+// or when every word of the text is.
+func searchCondition(search string) (string, []any) {
+	search = strings.ToLower(search)
+
+	var (
+		words = strings.Fields(search)
+		exprs = make([]string, 0, len(words))
+		args  = []any{"%" + escapeLike(search) + "%"}
+	)
+
+	for i, word := range words {
+		exprs = append(exprs, fmt.Sprintf(`search_text LIKE %%[%d]s ESCAPE '\'`, i+2))
+		args = append(args, "%"+escapeLike(word)+"%")
+	}
+
+	expr := `search_text LIKE %[1]s ESCAPE '\'`
+	if len(exprs) > 0 {
+		expr += " OR (" + strings.Join(exprs, " AND ") + ")"
+	}
+
+	return "(" + expr + ")", args
+}
+
+func filterTime(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+
+	return formatTime(t)
+}
+
+// escapeLike escapes wildcards, so value is matched literally by LIKE.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+
+	return " ASC"
+}
+
+func comparison(desc bool) string {
+	if desc {
+		return " < "
+	}
+
+	return " > "
+}
@@ -0,0 +1,47 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/repository/repotest"
+	"github.com/go-devs-ua/octagon/cfg"
+	migrate "github.com/rubenv/sql-migrate"
+	"github.com/stretchr/testify/require"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := ConnectDB(cfg.DB{Path: filepath.Join(t.TempDir(), "octagon.db")})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { db.Close() })
+
+	_, err = migrate.Exec(db, "sqlite3", &migrate.FileMigrationSource{Dir: "../../../migration/sqlite"}, migrate.Up)
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestRepo(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repository {
+		return NewRepo(openDB(t), time.Minute)
+	})
+}
+
+func TestRepo_Context(t *testing.T) {
+	db := openDB(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewRepo(db, time.Minute).FindUser(ctx, "4fddf9a4-fbd1-4083-98aa-e4d0e584e7bb")
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = NewRepo(db, time.Nanosecond).ListRoles(context.Background())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,164 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// ListRoles retrieves all roles along with their permissions.
+func (r Repo) ListRoles(ctx context.Context) ([]entities.Role, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			SELECT name, description,
+				(SELECT group_concat(permission) FROM 
+					(SELECT permission FROM "role_permission" WHERE role = "role".name ORDER BY permission))
+			FROM "role"
+			ORDER BY name;
+			`
+
+	rows, err := r.DB.QueryContext(ctx, SQL)
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while executing query: %w", domainError(err))
+	}
+
+	defer rows.Close()
+
+	var roles []entities.Role
+
+	for rows.Next() {
+		var (
+			role        entities.Role
+			permissions sql.NullString
+		)
+
+		if err := rows.Scan(&role.Name, &role.Description, &permissions); err != nil {
+			return nil, fmt.Errorf("error occurred while scaning object from query: %w", err)
+		}
+
+		role.Permissions = splitRoles(permissions)
+		roles = append(roles, role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration: %w", domainError(err))
+	}
+
+	return roles, nil
+}
+
+// FindPermissions retrieves distinct permissions granted by given roles.
+// Roles are passed as JSON array, as SQLite has no array parameters.
+func (r Repo) FindPermissions(ctx context.Context, roles []string) ([]string, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			SELECT DISTINCT permission
+			FROM "role_permission"
+			WHERE role IN (SELECT value FROM json_each($1))
+			ORDER BY permission;
+			`
+
+	names, err := json.Marshal(roles)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding roles: %w", err)
+	}
+
+	rows, err := r.DB.QueryContext(ctx, SQL, string(names))
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while executing query: %w", domainError(err))
+	}
+
+	defer rows.Close()
+
+	var permissions []string
+
+	for rows.Next() {
+		var permission string
+
+		if err := rows.Scan(&permission); err != nil {
+			return nil, fmt.Errorf("error occurred while scaning object from query: %w", err)
+		}
+
+		permissions = append(permissions, permission)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration: %w", domainError(err))
+	}
+
+	return permissions, nil
+}
+
+// AssignRole grants role to the user. Assigning already granted role is a no-op.
+// SQLite does not tell which foreign key is violated, so role is looked up first.
+func (r Repo) AssignRole(ctx context.Context, userID, role string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			INSERT INTO "user_role" (user_id, role)
+			SELECT $1, name FROM "role" WHERE name = $2
+			ON CONFLICT DO NOTHING
+			RETURNING role;
+			`
+
+	if err := r.DB.QueryRowContext(ctx, SQL, userID, role).Scan(&role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return r.checkRoleAssigned(ctx, userID, role)
+		}
+
+		// Role is known to exist, so it is the user who does not.
+		if isConstraintError(err, sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY) {
+			return entities.ErrNotFound.Wrap(err)
+		}
+
+		return fmt.Errorf("error inserting into database: %w", domainError(err))
+	}
+
+	return nil
+}
+
+// checkRoleAssigned tells apart unknown role from role already granted to the user.
+func (r Repo) checkRoleAssigned(ctx context.Context, userID, role string) error {
+	const SQL = `
+			SELECT EXISTS (SELECT 1 FROM "user_role" WHERE user_id = $1 AND role = $2);
+			`
+
+	var assigned bool
+
+	if err := r.DB.QueryRowContext(ctx, SQL, userID, role).Scan(&assigned); err != nil {
+		return fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	if !assigned {
+		return entities.ErrRoleNotFound
+	}
+
+	return nil
+}
+
+// RevokeRole takes role away from the user. Revoking role that is not granted is a no-op.
+func (r Repo) RevokeRole(ctx context.Context, userID, role string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			DELETE FROM "user_role"
+			WHERE user_id = $1 AND role = $2;
+			`
+
+	if _, err := r.DB.ExecContext(ctx, SQL, userID, role); err != nil {
+		return fmt.Errorf("error deleting from database: %w", domainError(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/google/uuid"
+)
+
+// AddRefreshToken stores hash of issued refresh token.
+func (r Repo) AddRefreshToken(ctx context.Context, token entities.RefreshToken) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			INSERT INTO "refresh_token" (id, user_id, token_hash, expires_at)
+			VALUES ($1, $2, $3, $4);
+			`
+
+	if _, err := r.DB.ExecContext(ctx, SQL, uuid.NewString(), token.UserID, token.TokenHash,
+		formatTime(token.ExpiresAt)); err != nil {
+		return fmt.Errorf("error inserting into database: %w", domainError(err))
+	}
+
+	return nil
+}
+
+// FindRefreshToken finds refresh token by its hash regardless of its state.
+func (r Repo) FindRefreshToken(ctx context.Context, tokenHash string) (*entities.RefreshToken, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			SELECT id, user_id, token_hash, expires_at, revoked_at
+			FROM "refresh_token"
+			WHERE token_hash = $1;
+			`
+
+	return scanRefreshToken(r.DB.QueryRowContext(ctx, SQL, tokenHash))
+}
+
+// RevokeRefreshToken atomically revokes active (not revoked and not expired)
+// refresh token and returns it, so the same token can not be used twice.
+func (r Repo) RevokeRefreshToken(ctx context.Context, tokenHash string) (*entities.RefreshToken, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			UPDATE "refresh_token"
+			SET revoked_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now')
+			WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > strftime('%Y-%m-%dT%H:%M:%fZ', 'now')
+			RETURNING id, user_id, token_hash, expires_at, revoked_at;
+			`
+
+	return scanRefreshToken(r.DB.QueryRowContext(ctx, SQL, tokenHash))
+}
+
+// DeleteRefreshToken deletes refresh token whether it is active or not.
+// Deleting unknown token is not an error.
+func (r Repo) DeleteRefreshToken(ctx context.Context, tokenHash string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			DELETE FROM "refresh_token"
+			WHERE token_hash = $1;
+			`
+
+	if _, err := r.DB.ExecContext(ctx, SQL, tokenHash); err != nil {
+		return fmt.Errorf("error deleting from database: %w", domainError(err))
+	}
+
+	return nil
+}
+
+// DeleteUserRefreshTokens deletes all refresh tokens of the user,
+// including revoked ones, so none of them is recognised anymore.
+func (r Repo) DeleteUserRefreshTokens(ctx context.Context, userID string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			DELETE FROM "refresh_token"
+			WHERE user_id = $1;
+			`
+
+	if _, err := r.DB.ExecContext(ctx, SQL, userID); err != nil {
+		return fmt.Errorf("error deleting from database: %w", domainError(err))
+	}
+
+	return nil
+}
+
+// scanRefreshToken scans token, parsing times kept as text.
+func scanRefreshToken(row *sql.Row) (*entities.RefreshToken, error) {
+	var (
+		token     entities.RefreshToken
+		expiresAt string
+		revokedAt sql.NullString
+	)
+
+	if err := row.Scan(&token.ID, &token.UserID, &token.TokenHash, &expiresAt, &revokedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entities.ErrTokenNotFound
+		}
+
+		return nil, fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	var err error
+
+	if token.ExpiresAt, err = time.Parse(timeLayout, expiresAt); err != nil {
+		return nil, fmt.Errorf("error parsing expiration time: %w", err)
+	}
+
+	if revokedAt.Valid {
+		t, err := time.Parse(timeLayout, revokedAt.String)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing revocation time: %w", err)
+		}
+
+		token.RevokedAt = &t
+	}
+
+	return &token, nil
+}
@@ -0,0 +1,542 @@
+// Package sqlite lives in repository dir and represents adapter layer
+// which enables interaction through a specific port and with a certain technology.
+// In this case sqlite will act for CRUD operations with SQLite database file,
+// so the app can be embedded without running postgres.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/google/uuid"
+)
+
+// timeLayout is format times are stored in.
+// It has fixed width, so text comparison of times is chronological.
+const timeLayout = "2006-01-02T15:04:05.000Z"
+
+// Repo wraps a database handle.
+// Every query is cancelled after QueryTimeout
+// unless context of the call is done earlier.
+// Zero QueryTimeout leaves queries bound by the context only.
+type Repo struct {
+	DB           *sql.DB
+	QueryTimeout time.Duration
+}
+
+// NewRepo will initialise new instance of Repo.
+func NewRepo(db *sql.DB, queryTimeout time.Duration) *Repo {
+	return &Repo{
+		DB:           db,
+		QueryTimeout: queryTimeout,
+	}
+}
+
+// withTimeout bounds ctx by query timeout.
+func (r Repo) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, r.QueryTimeout)
+}
+
+// AddUser method implements storing the user in the database
+// along with assigning default member role.
+// Password is expected to be already hashed.
+func (r Repo) AddUser(ctx context.Context, user entities.User) (string, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	id := uuid.NewString()
+
+	const (
+		insertUser = `
+			INSERT INTO "user" (id, first_name, last_name, email, password)
+			VALUES ($1, $2, $3, $4, $5);
+			`
+		insertRole = `
+			INSERT INTO "user_role" (user_id, role)
+			VALUES ($1, $2);
+			`
+	)
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer tx.Rollback() //nolint:errcheck // Rollback after commit is a no-op.
+
+	if err := execOne(ctx, tx, insertUser, id, user.FirstName, user.LastName, user.Email, user.Password); err != nil {
+		return "", fmt.Errorf("error inserting user into database: %w", err)
+	}
+
+	if err := execOne(ctx, tx, insertRole, id, entities.RoleMember); err != nil {
+		return "", fmt.Errorf("error inserting role into database: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("error committing transaction: %w", domainError(err))
+	}
+
+	return id, nil
+}
+
+// execOne executes statement expected to change exactly one row.
+func execOne(ctx context.Context, tx *sql.Tx, query string, args ...any) error {
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return domainError(err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error counting affected rows: %w", err)
+	}
+
+	if n != 1 {
+		return fmt.Errorf("%d rows affected instead of one", n)
+	}
+
+	return nil
+}
+
+// FindUser method implements logic of finding user in the database by ID.
+func (r Repo) FindUser(ctx context.Context, id string) (*entities.User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var (
+		user              entities.User
+		verifiedAt, roles sql.NullString
+	)
+
+	const SQL = `
+			SELECT id, first_name, COALESCE(last_name, ''), email, created_at, updated_at, verified_at,
+				(SELECT group_concat(role) FROM (SELECT role FROM "user_role" WHERE user_id = "user".id ORDER BY role))
+			FROM "user" 
+			WHERE id = $1
+			AND deleted_at is null;
+			`
+
+	if err := r.DB.QueryRowContext(ctx, SQL, id).Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.CreatedAt,
+		&user.UpdatedAt, &verifiedAt, &roles); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entities.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	user.VerifiedAt, user.Roles = verifiedAt.String, splitRoles(roles)
+
+	return &user, nil
+}
+
+// FindUserByEmail method implements logic of finding user in the database by email.
+// Unlike FindUser it also returns password hash, so it can be used for authentication.
+func (r Repo) FindUserByEmail(ctx context.Context, email string) (*entities.User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var (
+		user              entities.User
+		verifiedAt, roles sql.NullString
+	)
+
+	const SQL = `
+			SELECT id, first_name, COALESCE(last_name, ''), email, password, created_at, verified_at,
+				(SELECT group_concat(role) FROM (SELECT role FROM "user_role" WHERE user_id = "user".id ORDER BY role))
+			FROM "user" 
+			WHERE email = $1
+			AND deleted_at is null;
+			`
+
+	if err := r.DB.QueryRowContext(ctx, SQL, email).Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Password,
+		&user.CreatedAt, &verifiedAt, &roles); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entities.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	user.VerifiedAt, user.Roles = verifiedAt.String, splitRoles(roles)
+
+	return &user, nil
+}
+
+// GetAllUsers retrieves list of users from database.
+func (r Repo) GetAllUsers(ctx context.Context, params entities.QueryParams) ([]entities.User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	keys, err := entities.ParseSort(params.Sort)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing sort: %w", err)
+	}
+
+	if params.UsesCursor() {
+		return r.getUsersAfter(ctx, params, keys)
+	}
+
+	const SQL = `
+			SELECT id, email, first_name, COALESCE(last_name, ''), created_at, updated_at, verified_at, deleted_at,
+				(SELECT group_concat(role) FROM (SELECT role FROM "user_role" WHERE user_id = "user".id ORDER BY role))
+			FROM "user" 
+			WHERE %s
+			ORDER BY %s
+			LIMIT  %s
+			OFFSET %s;
+	`
+
+	where := userFilter(params.Filter)
+	limit, offset := len(where.args)+1, len(where.args)+2
+	query := fmt.Sprintf(SQL, where, orderBy(keys), fmt.Sprintf("$%d", limit), fmt.Sprintf("$%d", offset))
+
+	rows, err := r.DB.QueryContext(ctx, query, append(where.args, params.Limit, params.Offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while executing query: %w", domainError(err))
+	}
+
+	return scanUsers(rows)
+}
+
+// CountUsers counts users matching the filter.
+func (r Repo) CountUsers(ctx context.Context, filter entities.Filter) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			SELECT COUNT(*) 
+			FROM "user" 
+			WHERE %s;
+	`
+
+	var n int64
+
+	where := userFilter(filter)
+	if err := r.DB.QueryRowContext(ctx, fmt.Sprintf(SQL, where), where.args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	return n, nil
+}
+
+// getUsersAfter retrieves page of users which starts right after the cursor.
+func (r Repo) getUsersAfter(ctx context.Context, params entities.QueryParams, keys []entities.SortKey) ([]entities.User, error) {
+	const SQL = `
+			SELECT id, email, first_name, COALESCE(last_name, ''), created_at, updated_at, verified_at, deleted_at,
+				(SELECT group_concat(role) FROM (SELECT role FROM "user_role" WHERE user_id = "user".id ORDER BY role))
+			FROM "user" 
+			WHERE %s
+			AND (%s)
+			ORDER BY %s
+			LIMIT %s;
+	`
+
+	where := userFilter(params.Filter)
+	args := append(where.args, params.Limit)
+	limit := fmt.Sprintf("$%d", len(args))
+	predicate := "TRUE"
+
+	if params.Cursor != "" {
+		cursor, err := entities.DecodeCursor(params.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding cursor: %w", err)
+		}
+
+		if len(cursor.Keys) != len(keys) {
+			return nil, entities.ErrMalformedCursor
+		}
+
+		var after []any
+
+		predicate, after = keysetAfter(keys, cursor, len(args)+1)
+		args = append(args, after...)
+	}
+
+	rows, err := r.DB.QueryContext(ctx, fmt.Sprintf(SQL, where, predicate, orderBy(keys), limit), args...)
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while executing query: %w", domainError(err))
+	}
+
+	return scanUsers(rows)
+}
+
+func scanUsers(rows *sql.Rows) ([]entities.User, error) {
+	defer rows.Close()
+
+	var users []entities.User
+
+	for rows.Next() {
+		var (
+			user                         entities.User
+			verifiedAt, deletedAt, roles sql.NullString
+		)
+
+		if err := rows.Scan(&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt,
+			&verifiedAt, &deletedAt, &roles); err != nil {
+			return nil, fmt.Errorf("error occurred while scaning object from query: %w", err)
+		}
+
+		user.VerifiedAt, user.DeletedAt, user.Roles = verifiedAt.String, deletedAt.String, splitRoles(roles)
+
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during iteration: %w", domainError(err))
+	}
+
+	return users, nil
+}
+
+// UpdateUser applies patch to the user and returns updated user.
+// Changing email resets its verification.
+func (r Repo) UpdateUser(ctx context.Context, id string, patch entities.UserPatch) (*entities.User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var (
+		user              entities.User
+		verifiedAt, roles sql.NullString
+	)
+
+	const SQL = `
+			UPDATE "user" 
+			SET first_name = COALESCE($2, first_name),
+				last_name = COALESCE($3, last_name),
+				email = COALESCE($4, email),
+				verified_at = CASE WHEN COALESCE($4, email) = email THEN verified_at END,
+				updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now')
+			WHERE id = $1 AND deleted_at IS NULL 
+			RETURNING id, first_name, COALESCE(last_name, ''), email, created_at, updated_at, verified_at,
+				(SELECT group_concat(role) FROM (SELECT role FROM "user_role" WHERE user_id = "user".id ORDER BY role));
+			`
+
+	if err := r.DB.QueryRowContext(ctx, SQL, id, patch.FirstName, patch.LastName, patch.Email).Scan(&user.ID, &user.FirstName,
+		&user.LastName, &user.Email, &user.CreatedAt, &user.UpdatedAt, &verifiedAt, &roles); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entities.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	user.VerifiedAt, user.Roles = verifiedAt.String, splitRoles(roles)
+
+	return &user, nil
+}
+
+// MarkVerified records that user confirmed ownership of the email.
+// Verifying already verified user keeps original verification time.
+func (r Repo) MarkVerified(ctx context.Context, id string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			UPDATE "user" 
+			SET verified_at = COALESCE(verified_at, strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+				updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now')
+			WHERE id = $1 AND deleted_at IS NULL 
+			RETURNING id;
+			`
+
+	if err := r.DB.QueryRowContext(ctx, SQL, id).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.ErrNotFound
+		}
+
+		return fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	return nil
+}
+
+// RestoreUser undoes deletion of the user deleted after given time
+// and returns restored user.
+func (r Repo) RestoreUser(ctx context.Context, id string, deletedAfter time.Time) (*entities.User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var (
+		user              entities.User
+		verifiedAt, roles sql.NullString
+	)
+
+	const SQL = `
+			UPDATE "user" 
+			SET deleted_at = NULL,
+				updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now')
+			WHERE id = $1 AND deleted_at > $2 AND anonymized_at IS NULL 
+			RETURNING id, first_name, COALESCE(last_name, ''), email, created_at, updated_at, verified_at,
+				(SELECT group_concat(role) FROM (SELECT role FROM "user_role" WHERE user_id = "user".id ORDER BY role));
+			`
+
+	if err := r.DB.QueryRowContext(ctx, SQL, id, formatTime(deletedAfter)).Scan(&user.ID, &user.FirstName, &user.LastName,
+		&user.Email, &user.CreatedAt, &user.UpdatedAt, &verifiedAt, &roles); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entities.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	user.VerifiedAt, user.Roles = verifiedAt.String, splitRoles(roles)
+
+	return &user, nil
+}
+
+// PurgeUsers removes users deleted before given time
+// along with all their data and returns number of removed users.
+func (r Repo) PurgeUsers(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			DELETE FROM "user" 
+			WHERE deleted_at < $1;
+			`
+
+	res, err := r.DB.ExecContext(ctx, SQL, formatTime(deletedBefore))
+	if err != nil {
+		return 0, fmt.Errorf("error deleting from database: %w", domainError(err))
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error counting deleted rows: %w", err)
+	}
+
+	return n, nil
+}
+
+// AnonymizeUsers wipes personal data and credentials of users deleted before given time,
+// keeping rows themselves, and returns number of anonymised users.
+// SQLite has no data-modifying CTEs, so tokens are deleted by separate statements of one transaction.
+func (r Repo) AnonymizeUsers(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const (
+		deleteRefreshTokens = `
+			DELETE FROM "refresh_token" 
+			WHERE user_id IN (SELECT id FROM "user" WHERE deleted_at < $1 AND anonymized_at IS NULL);
+			`
+		deleteUserTokens = `
+			DELETE FROM "user_token" 
+			WHERE user_id IN (SELECT id FROM "user" WHERE deleted_at < $1 AND anonymized_at IS NULL);
+			`
+		anonymize = `
+			UPDATE "user" 
+			SET first_name = 'Deleted',
+				last_name = NULL,
+				email = 'deleted-' || id || '@invalid',
+				password = '',
+				anonymized_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now'),
+				updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now')
+			WHERE deleted_at < $1 AND anonymized_at IS NULL;
+			`
+	)
+
+	before := formatTime(deletedBefore)
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer tx.Rollback() //nolint:errcheck // Rollback after commit is a no-op.
+
+	if _, err := tx.ExecContext(ctx, deleteRefreshTokens, before); err != nil {
+		return 0, fmt.Errorf("error deleting refresh tokens from database: %w", domainError(err))
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteUserTokens, before); err != nil {
+		return 0, fmt.Errorf("error deleting user tokens from database: %w", domainError(err))
+	}
+
+	res, err := tx.ExecContext(ctx, anonymize, before)
+	if err != nil {
+		return 0, fmt.Errorf("error updating database: %w", domainError(err))
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error counting updated rows: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing transaction: %w", domainError(err))
+	}
+
+	return n, nil
+}
+
+// UpdatePassword replaces password hash of the user.
+func (r Repo) UpdatePassword(ctx context.Context, user entities.User) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			UPDATE "user" 
+			SET password = $2,
+				updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now')
+			WHERE id = $1 AND deleted_at IS NULL 
+			RETURNING id;
+			`
+
+	if err := r.DB.QueryRowContext(ctx, SQL, user.ID, user.Password).Scan(&user.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.ErrNotFound
+		}
+
+		return fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	return nil
+}
+
+// DeleteUser removes user from database.
+func (r Repo) DeleteUser(ctx context.Context, user entities.User) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			UPDATE "user" 
+			SET deleted_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now'),
+				updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now')
+			WHERE id = $1 AND deleted_at IS NULL 
+			RETURNING id;
+			`
+
+	if err := r.DB.QueryRowContext(ctx, SQL, user.ID).Scan(&user.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entities.ErrNotFound
+		}
+
+		return fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	return nil
+}
+
+// splitRoles splits comma separated roles aggregated by group_concat.
+func splitRoles(roles sql.NullString) []string {
+	if roles.String == "" {
+		return []string{}
+	}
+
+	return strings.Split(roles.String, ",")
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
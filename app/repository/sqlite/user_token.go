@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/google/uuid"
+)
+
+// AddUserToken stores hash of issued one-time token.
+func (r Repo) AddUserToken(ctx context.Context, token entities.UserToken) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			INSERT INTO "user_token" (id, user_id, purpose, token_hash, expires_at)
+			VALUES ($1, $2, $3, $4, $5);
+			`
+
+	if _, err := r.DB.ExecContext(ctx, SQL, uuid.NewString(), token.UserID, token.Purpose, token.TokenHash,
+		formatTime(token.ExpiresAt)); err != nil {
+		return fmt.Errorf("error inserting into database: %w", domainError(err))
+	}
+
+	return nil
+}
+
+// ConsumeUserToken atomically marks unused and not expired token
+// issued for given purpose as used and returns it.
+func (r Repo) ConsumeUserToken(ctx context.Context, tokenHash, purpose string) (*entities.UserToken, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var (
+		token     entities.UserToken
+		expiresAt string
+	)
+
+	const SQL = `
+			UPDATE "user_token"
+			SET used_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now')
+			WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL 
+				AND expires_at > strftime('%Y-%m-%dT%H:%M:%fZ', 'now')
+			RETURNING id, user_id, purpose, token_hash, expires_at;
+			`
+
+	if err := r.DB.QueryRowContext(ctx, SQL, tokenHash, purpose).Scan(&token.ID, &token.UserID, &token.Purpose, &token.TokenHash,
+		&expiresAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entities.ErrTokenNotFound
+		}
+
+		return nil, fmt.Errorf("internal error while scanning row: %w", domainError(err))
+	}
+
+	var err error
+
+	if token.ExpiresAt, err = time.Parse(timeLayout, expiresAt); err != nil {
+		return nil, fmt.Errorf("error parsing expiration time: %w", err)
+	}
+
+	return &token, nil
+}
+
+// RevokeUserTokens marks all unused tokens issued to the user
+// for given purpose as used, so none of them can be consumed anymore.
+func (r Repo) RevokeUserTokens(ctx context.Context, userID, purpose string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	const SQL = `
+			UPDATE "user_token"
+			SET used_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now')
+			WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL;
+			`
+
+	if _, err := r.DB.ExecContext(ctx, SQL, userID, purpose); err != nil {
+		return fmt.Errorf("error updating database: %w", domainError(err))
+	}
+
+	return nil
+}
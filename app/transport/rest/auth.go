@@ -0,0 +1,366 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/go-devs-ua/octagon/lgr"
+	"github.com/gorilla/mux"
+)
+
+// LoginRequest holds on user credentials.
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// RefreshRequest holds on refresh token
+// that is going to be exchanged or revoked.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenResponse will wrap issued tokens
+// that will be sent in JSON format.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func makeTokenResponse(pair *entities.TokenPair) TokenResponse {
+	return TokenResponse{
+		AccessToken:  pair.AccessToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    int64(time.Until(pair.ExpiresAt).Seconds()),
+		RefreshToken: pair.RefreshToken,
+	}
+}
+
+// Login will handle user authentication.
+func (ah AuthHandler) Login(w http.ResponseWriter, req *http.Request) {
+	logger := ah.logger.Ctx(req.Context())
+
+	var creds LoginRequest
+	if !ah.decode(w, req, &creds) {
+		return
+	}
+
+	var verr entities.ValidationError
+
+	verr.Require("email", creds.Email)
+	verr.Require("password", creds.Password)
+
+	if err := verr.Err(); err != nil {
+		writeError(w, err, logger)
+
+		return
+	}
+
+	pair, err := ah.usecase.Login(req.Context(), creds.Email, creds.Password)
+	if err != nil {
+		if isInternal(err) {
+			logger.Errorw("Internal error while logging in.", "email", creds.Email, "error", err.Error())
+		} else {
+			logger.Debugw("Login rejected.", "email", creds.Email, "error", err.Error())
+		}
+
+		writeError(w, err, logger)
+
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, makeTokenResponse(pair), logger)
+}
+
+// Refresh will handle exchanging refresh token for new pair of tokens.
+func (ah AuthHandler) Refresh(w http.ResponseWriter, req *http.Request) {
+	logger := ah.logger.Ctx(req.Context())
+
+	refreshToken, ok := ah.decodeRefreshToken(w, req)
+	if !ok {
+		return
+	}
+
+	pair, err := ah.usecase.Refresh(req.Context(), refreshToken)
+	if err != nil {
+		if isInternal(err) {
+			logger.Errorw("Internal error while refreshing token.", "error", err.Error())
+		}
+
+		writeError(w, err, logger)
+
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, makeTokenResponse(pair), logger)
+}
+
+// Logout will handle revoking of refresh token.
+func (ah AuthHandler) Logout(w http.ResponseWriter, req *http.Request) {
+	logger := ah.logger.Ctx(req.Context())
+
+	refreshToken, ok := ah.decodeRefreshToken(w, req)
+	if !ok {
+		return
+	}
+
+	if err := ah.usecase.Logout(req.Context(), refreshToken); err != nil {
+		logger.Errorw("Internal error while logging out.", "error", err.Error())
+		writeError(w, err, logger)
+
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusNoContent, nil, logger)
+}
+
+func (ah AuthHandler) decodeRefreshToken(w http.ResponseWriter, req *http.Request) (string, bool) {
+	logger := ah.logger.Ctx(req.Context())
+
+	var body RefreshRequest
+	if !ah.decode(w, req, &body) {
+		return "", false
+	}
+
+	var verr entities.ValidationError
+
+	verr.Require("refresh_token", body.RefreshToken)
+
+	if err := verr.Err(); err != nil {
+		writeError(w, err, logger)
+
+		return "", false
+	}
+
+	return body.RefreshToken, true
+}
+
+// ChangePasswordRequest holds on current and new passwords.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// PasswordResetRequest holds on email of the user who forgot password.
+type PasswordResetRequest struct {
+	Email string `json:"email"`
+}
+
+// PasswordResetConfirmRequest holds on reset token and new password.
+type PasswordResetConfirmRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// ChangePassword will handle changing password of authenticated user.
+// Users may change only their own password.
+func (ah AuthHandler) ChangePassword(w http.ResponseWriter, req *http.Request) {
+	logger := ah.logger.Ctx(req.Context())
+
+	id := mux.Vars(req)["id"]
+
+	principal, ok := PrincipalFromContext(req.Context())
+	if !ok {
+		writeProblem(w, newProblem(http.StatusUnauthorized, CodeUnauthorized, "authentication required"), logger)
+
+		return
+	}
+
+	if principal.UserID != id {
+		writeProblem(w, newProblem(http.StatusForbidden, CodeForbidden, "not allowed to act on this account"), logger)
+
+		return
+	}
+
+	var body ChangePasswordRequest
+	if !ah.decode(w, req, &body) {
+		return
+	}
+
+	var verr entities.ValidationError
+
+	verr.Require("current_password", body.CurrentPassword)
+	verr.CheckPassword("new_password", body.NewPassword)
+
+	if err := verr.Err(); err != nil {
+		writeError(w, err, logger)
+
+		return
+	}
+
+	if err := ah.usecase.ChangePassword(req.Context(), id, body.CurrentPassword, body.NewPassword); err != nil {
+		if isInternal(err) {
+			logger.Errorw("Internal error while changing password.", "ID", id, "error", err.Error())
+		}
+
+		writeError(w, err, logger)
+
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusNoContent, nil, logger)
+	logger.Debugw("Password successfully changed", "ID", id)
+}
+
+// RequestPasswordReset will handle issuing of password reset token.
+// Response has no body, so it does not reveal whether email is registered.
+func (ah AuthHandler) RequestPasswordReset(w http.ResponseWriter, req *http.Request) {
+	logger := ah.logger.Ctx(req.Context())
+
+	var body PasswordResetRequest
+	if !ah.decode(w, req, &body) {
+		return
+	}
+
+	var verr entities.ValidationError
+
+	verr.Require("email", body.Email)
+
+	if err := verr.Err(); err != nil {
+		writeError(w, err, logger)
+
+		return
+	}
+
+	if err := ah.usecase.RequestPasswordReset(req.Context(), body.Email); err != nil {
+		logger.Errorw("Internal error while requesting password reset.", "error", err.Error())
+		writeError(w, err, logger)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ConfirmPasswordReset will handle setting new password by reset token.
+func (ah AuthHandler) ConfirmPasswordReset(w http.ResponseWriter, req *http.Request) {
+	logger := ah.logger.Ctx(req.Context())
+
+	var body PasswordResetConfirmRequest
+	if !ah.decode(w, req, &body) {
+		return
+	}
+
+	var verr entities.ValidationError
+
+	verr.Require("token", body.Token)
+	verr.CheckPassword("new_password", body.NewPassword)
+
+	if err := verr.Err(); err != nil {
+		writeError(w, err, logger)
+
+		return
+	}
+
+	if err := ah.usecase.ResetPassword(req.Context(), body.Token, body.NewPassword); err != nil {
+		if isInternal(err) {
+			logger.Errorw("Internal error while resetting password.", "error", err.Error())
+		}
+
+		writeTokenError(w, err, logger)
+
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusNoContent, nil, logger)
+}
+
+// VerificationRequest holds on email verification has to be resent to.
+type VerificationRequest struct {
+	Email string `json:"email"`
+}
+
+// VerifyEmail will handle confirming email by verification token
+// passed in token query parameter.
+func (ah AuthHandler) VerifyEmail(w http.ResponseWriter, req *http.Request) {
+	logger := ah.logger.Ctx(req.Context())
+
+	verificationToken := req.URL.Query().Get("token")
+
+	var verr entities.ValidationError
+
+	verr.Require("token", verificationToken)
+
+	if err := verr.Err(); err != nil {
+		writeError(w, err, logger)
+
+		return
+	}
+
+	if err := ah.usecase.VerifyEmail(req.Context(), verificationToken); err != nil {
+		if isInternal(err) {
+			logger.Errorw("Internal error while verifying email.", "error", err.Error())
+		}
+
+		writeTokenError(w, err, logger)
+
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusNoContent, nil, logger)
+}
+
+// RequestVerification will handle resending of email verification token.
+// Response has no body, so it does not reveal whether email is registered or already verified.
+func (ah AuthHandler) RequestVerification(w http.ResponseWriter, req *http.Request) {
+	logger := ah.logger.Ctx(req.Context())
+
+	var body VerificationRequest
+	if !ah.decode(w, req, &body) {
+		return
+	}
+
+	var verr entities.ValidationError
+
+	verr.Require("email", body.Email)
+
+	if err := verr.Err(); err != nil {
+		writeError(w, err, logger)
+
+		return
+	}
+
+	if err := ah.usecase.RequestVerification(req.Context(), body.Email); err != nil {
+		logger.Errorw("Internal error while requesting email verification.", "error", err.Error())
+		writeError(w, err, logger)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// writeTokenError reports one-time token passed in the request as bad request,
+// unlike access and refresh tokens which are rejected as unauthorized.
+func writeTokenError(w http.ResponseWriter, err error, logger *lgr.Logger) {
+	problem := problemFor(err)
+	if errors.Is(err, entities.ErrInvalidToken) {
+		problem = newProblem(http.StatusBadRequest, problem.Code, problem.Detail)
+	}
+
+	writeProblem(w, problem, logger)
+}
+
+// decode decodes JSON request body into v
+// writing bad request response on failure.
+func (ah AuthHandler) decode(w http.ResponseWriter, req *http.Request, v any) bool {
+	logger := ah.logger.Ctx(req.Context())
+
+	if err := json.NewDecoder(req.Body).Decode(v); err != nil {
+		writeDecodeError(w, err, logger)
+		logger.Errorw("Failed decoding JSON from request.", "method", req.Method, "path", req.URL.Path, "error", err.Error())
+
+		return false
+	}
+
+	if err := req.Body.Close(); err != nil {
+		logger.Warnw("Failed closing request.", "method", req.Method, "path", req.URL.Path, "error", err.Error())
+	}
+
+	return true
+}
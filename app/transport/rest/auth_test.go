@@ -0,0 +1,465 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/go-devs-ua/octagon/lgr"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthHandler_Login(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	tests := map[string]struct {
+		requestBody        string
+		usecaseConstructor func(ctrl *gomock.Controller) AuthUsecase
+		expStatusCode      int
+		expResponseBody    string
+	}{
+		"success": {
+			requestBody: `{"email": "john@example.com", "password": "12345678Qwerty"}`,
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				mock := NewMockAuthUsecase(ctrl)
+
+				mock.EXPECT().Login(gomock.Any(), "john@example.com", "12345678Qwerty").Return(&entities.TokenPair{
+					AccessToken:  "access",
+					ExpiresAt:    time.Now().Add(time.Hour),
+					RefreshToken: "refresh",
+				}, nil).Times(1)
+
+				return mock
+			},
+			expStatusCode: http.StatusOK,
+		},
+		"invalid_credentials": {
+			requestBody: `{"email": "john@example.com", "password": "wrong"}`,
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				mock := NewMockAuthUsecase(ctrl)
+				mock.EXPECT().Login(gomock.Any(), "john@example.com", "wrong").Return(nil, entities.ErrInvalidCredentials).Times(1)
+
+				return mock
+			},
+			expStatusCode:   http.StatusUnauthorized,
+			expResponseBody: `{"type":"urn:octagon:problem:invalid_credentials","title":"Unauthorized","status":401,"code":"invalid_credentials","detail":"invalid email or password"}`,
+		},
+		"unverified_email": {
+			requestBody: `{"email": "john@example.com", "password": "12345678Qwerty"}`,
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				mock := NewMockAuthUsecase(ctrl)
+				mock.EXPECT().Login(gomock.Any(), "john@example.com", "12345678Qwerty").Return(nil, entities.ErrEmailNotVerified).Times(1)
+
+				return mock
+			},
+			expStatusCode:   http.StatusForbidden,
+			expResponseBody: `{"type":"urn:octagon:problem:email_not_verified","title":"Forbidden","status":403,"code":"email_not_verified","detail":"email is not verified"}`,
+		},
+		"missing_password": {
+			requestBody: `{"email": "john@example.com"}`,
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				return nil
+			},
+			expStatusCode:   http.StatusBadRequest,
+			expResponseBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"password","code":"required","message":"is required"}]}`,
+		},
+		"internal_server_error": {
+			requestBody: `{"email": "john@example.com", "password": "12345678Qwerty"}`,
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				mock := NewMockAuthUsecase(ctrl)
+				mock.EXPECT().Login(gomock.Any(), "john@example.com", "12345678Qwerty").Return(nil, errors.New("Internal error")).Times(1)
+
+				return mock
+			},
+			expStatusCode:   http.StatusInternalServerError,
+			expResponseBody: `{"type":"urn:octagon:problem:internal_error","title":"Internal Server Error","status":500,"code":"internal_error"}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ah := AuthHandler{
+				usecase: tt.usecaseConstructor(ctrl),
+				logger:  logger,
+			}
+			resp := httptest.NewRecorder()
+			ah.Login(resp, httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(tt.requestBody)))
+
+			require.Equal(t, tt.expStatusCode, resp.Code)
+			if len(tt.expResponseBody) > 0 {
+				require.JSONEq(t, tt.expResponseBody, resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthHandler_Refresh(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	tests := map[string]struct {
+		requestBody        string
+		usecaseConstructor func(ctrl *gomock.Controller) AuthUsecase
+		expStatusCode      int
+		expResponseBody    string
+	}{
+		"success": {
+			requestBody: `{"refresh_token": "refresh"}`,
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				mock := NewMockAuthUsecase(ctrl)
+
+				mock.EXPECT().Refresh(gomock.Any(), "refresh").Return(&entities.TokenPair{
+					AccessToken:  "access",
+					ExpiresAt:    time.Now().Add(time.Hour),
+					RefreshToken: "rotated",
+				}, nil).Times(1)
+
+				return mock
+			},
+			expStatusCode: http.StatusOK,
+		},
+		"invalid_token": {
+			requestBody: `{"refresh_token": "revoked"}`,
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				mock := NewMockAuthUsecase(ctrl)
+				mock.EXPECT().Refresh(gomock.Any(), "revoked").Return(nil, entities.ErrInvalidToken).Times(1)
+
+				return mock
+			},
+			expStatusCode:   http.StatusUnauthorized,
+			expResponseBody: `{"type":"urn:octagon:problem:invalid_token","title":"Unauthorized","status":401,"code":"invalid_token","detail":"invalid or expired token"}`,
+		},
+		"missing_token": {
+			requestBody: `{}`,
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				return nil
+			},
+			expStatusCode:   http.StatusBadRequest,
+			expResponseBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"refresh_token","code":"required","message":"is required"}]}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ah := AuthHandler{
+				usecase: tt.usecaseConstructor(ctrl),
+				logger:  logger,
+			}
+			resp := httptest.NewRecorder()
+			ah.Refresh(resp, httptest.NewRequest(http.MethodPost, "/auth/refresh", strings.NewReader(tt.requestBody)))
+
+			require.Equal(t, tt.expStatusCode, resp.Code)
+			if len(tt.expResponseBody) > 0 {
+				require.JSONEq(t, tt.expResponseBody, resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthHandler_ChangePassword(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	const id = "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc"
+
+	tests := map[string]struct {
+		requestBody        string
+		principal          *entities.Principal
+		usecaseConstructor func(ctrl *gomock.Controller) AuthUsecase
+		expStatusCode      int
+		expResponseBody    string
+	}{
+		"success": {
+			requestBody: `{"current_password": "12345678Qwerty", "new_password": "87654321Qwerty"}`,
+			principal:   &entities.Principal{UserID: id},
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				mock := NewMockAuthUsecase(ctrl)
+				mock.EXPECT().ChangePassword(gomock.Any(), id, "12345678Qwerty", "87654321Qwerty").Return(nil).Times(1)
+
+				return mock
+			},
+			expStatusCode: http.StatusNoContent,
+		},
+		"wrong_current_password": {
+			requestBody: `{"current_password": "wrong", "new_password": "87654321Qwerty"}`,
+			principal:   &entities.Principal{UserID: id},
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				mock := NewMockAuthUsecase(ctrl)
+				mock.EXPECT().ChangePassword(gomock.Any(), id, "wrong", "87654321Qwerty").Return(entities.ErrWrongPassword).Times(1)
+
+				return mock
+			},
+			expStatusCode:   http.StatusForbidden,
+			expResponseBody: `{"type":"urn:octagon:problem:wrong_password","title":"Forbidden","status":403,"code":"wrong_password","detail":"current password is incorrect"}`,
+		},
+		"weak_new_password": {
+			requestBody: `{"current_password": "12345678Qwerty", "new_password": "short"}`,
+			principal:   &entities.Principal{UserID: id},
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				return nil
+			},
+			expStatusCode:   http.StatusBadRequest,
+			expResponseBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"new_password","code":"invalid","message":"must be 8 to 256 printable characters without spaces"}]}`,
+		},
+		"missing_passwords": {
+			requestBody: `{}`,
+			principal:   &entities.Principal{UserID: id},
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				return nil
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponseBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[` +
+				`{"field":"current_password","code":"required","message":"is required"},` +
+				`{"field":"new_password","code":"required","message":"is required"}]}`,
+		},
+		"foreign_account": {
+			requestBody: `{"current_password": "12345678Qwerty", "new_password": "87654321Qwerty"}`,
+			principal: &entities.Principal{
+				UserID:      "4fddf9a4-fbd1-4083-98aa-e4d0e584e7bb",
+				Permissions: []string{entities.PermUsersManage},
+			},
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				return nil
+			},
+			expStatusCode:   http.StatusForbidden,
+			expResponseBody: `{"type":"urn:octagon:problem:forbidden","title":"Forbidden","status":403,"code":"forbidden","detail":"not allowed to act on this account"}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ah := AuthHandler{
+				usecase: tt.usecaseConstructor(ctrl),
+				logger:  logger,
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "*", strings.NewReader(tt.requestBody))
+			req = mux.SetURLVars(req, map[string]string{"id": id})
+			req = req.WithContext(context.WithValue(req.Context(), principalCtxKey, tt.principal))
+
+			resp := httptest.NewRecorder()
+			ah.ChangePassword(resp, req)
+
+			require.Equal(t, tt.expStatusCode, resp.Code)
+			if len(tt.expResponseBody) > 0 {
+				require.JSONEq(t, tt.expResponseBody, resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthHandler_RequestPasswordReset(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	tests := map[string]struct {
+		requestBody        string
+		usecaseConstructor func(ctrl *gomock.Controller) AuthUsecase
+		expStatusCode      int
+		expResponseBody    string
+	}{
+		"success": {
+			requestBody: `{"email": "john@example.com"}`,
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				mock := NewMockAuthUsecase(ctrl)
+				mock.EXPECT().RequestPasswordReset(gomock.Any(), "john@example.com").Return(nil).Times(1)
+
+				return mock
+			},
+			expStatusCode: http.StatusAccepted,
+		},
+		"missing_email": {
+			requestBody: `{}`,
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				return nil
+			},
+			expStatusCode:   http.StatusBadRequest,
+			expResponseBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"email","code":"required","message":"is required"}]}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ah := AuthHandler{
+				usecase: tt.usecaseConstructor(ctrl),
+				logger:  logger,
+			}
+			resp := httptest.NewRecorder()
+			ah.RequestPasswordReset(resp, httptest.NewRequest(http.MethodPost, "/auth/password-reset",
+				strings.NewReader(tt.requestBody)))
+
+			require.Equal(t, tt.expStatusCode, resp.Code)
+			if len(tt.expResponseBody) > 0 {
+				require.JSONEq(t, tt.expResponseBody, resp.Body.String())
+			} else {
+				require.Empty(t, resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthHandler_ConfirmPasswordReset(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	tests := map[string]struct {
+		requestBody        string
+		usecaseConstructor func(ctrl *gomock.Controller) AuthUsecase
+		expStatusCode      int
+		expResponseBody    string
+	}{
+		"success": {
+			requestBody: `{"token": "reset", "new_password": "87654321Qwerty"}`,
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				mock := NewMockAuthUsecase(ctrl)
+				mock.EXPECT().ResetPassword(gomock.Any(), "reset", "87654321Qwerty").Return(nil).Times(1)
+
+				return mock
+			},
+			expStatusCode: http.StatusNoContent,
+		},
+		"used_token": {
+			requestBody: `{"token": "used", "new_password": "87654321Qwerty"}`,
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				mock := NewMockAuthUsecase(ctrl)
+				mock.EXPECT().ResetPassword(gomock.Any(), "used", "87654321Qwerty").Return(entities.ErrInvalidToken).Times(1)
+
+				return mock
+			},
+			expStatusCode:   http.StatusBadRequest,
+			expResponseBody: `{"type":"urn:octagon:problem:invalid_token","title":"Bad Request","status":400,"code":"invalid_token","detail":"invalid or expired token"}`,
+		},
+		"missing_token": {
+			requestBody: `{"new_password": "87654321Qwerty"}`,
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				return nil
+			},
+			expStatusCode:   http.StatusBadRequest,
+			expResponseBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"token","code":"required","message":"is required"}]}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ah := AuthHandler{
+				usecase: tt.usecaseConstructor(ctrl),
+				logger:  logger,
+			}
+			resp := httptest.NewRecorder()
+			ah.ConfirmPasswordReset(resp, httptest.NewRequest(http.MethodPost, "/auth/password-reset/confirm",
+				strings.NewReader(tt.requestBody)))
+
+			require.Equal(t, tt.expStatusCode, resp.Code)
+			if len(tt.expResponseBody) > 0 {
+				require.JSONEq(t, tt.expResponseBody, resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthHandler_VerifyEmail(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	tests := map[string]struct {
+		target             string
+		usecaseConstructor func(ctrl *gomock.Controller) AuthUsecase
+		expStatusCode      int
+		expResponseBody    string
+	}{
+		"success": {
+			target: "/auth/verify?token=verify",
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				mock := NewMockAuthUsecase(ctrl)
+				mock.EXPECT().VerifyEmail(gomock.Any(), "verify").Return(nil).Times(1)
+
+				return mock
+			},
+			expStatusCode: http.StatusNoContent,
+		},
+		"expired_token": {
+			target: "/auth/verify?token=expired",
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				mock := NewMockAuthUsecase(ctrl)
+				mock.EXPECT().VerifyEmail(gomock.Any(), "expired").Return(entities.ErrInvalidToken).Times(1)
+
+				return mock
+			},
+			expStatusCode:   http.StatusBadRequest,
+			expResponseBody: `{"type":"urn:octagon:problem:invalid_token","title":"Bad Request","status":400,"code":"invalid_token","detail":"invalid or expired token"}`,
+		},
+		"missing_token": {
+			target: "/auth/verify",
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				return nil
+			},
+			expStatusCode:   http.StatusBadRequest,
+			expResponseBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"token","code":"required","message":"is required"}]}`,
+		},
+		"internal_server_error": {
+			target: "/auth/verify?token=verify",
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				mock := NewMockAuthUsecase(ctrl)
+				mock.EXPECT().VerifyEmail(gomock.Any(), "verify").Return(errors.New("Internal error")).Times(1)
+
+				return mock
+			},
+			expStatusCode:   http.StatusInternalServerError,
+			expResponseBody: `{"type":"urn:octagon:problem:internal_error","title":"Internal Server Error","status":500,"code":"internal_error"}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ah := AuthHandler{
+				usecase: tt.usecaseConstructor(ctrl),
+				logger:  logger,
+			}
+			resp := httptest.NewRecorder()
+			ah.VerifyEmail(resp, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			require.Equal(t, tt.expStatusCode, resp.Code)
+			if len(tt.expResponseBody) > 0 {
+				require.JSONEq(t, tt.expResponseBody, resp.Body.String())
+			}
+		})
+	}
+}
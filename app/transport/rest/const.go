@@ -1,9 +1,10 @@
 package rest
 
+import "time"
+
 const (
-	MsgInternalSeverErr = "Internal server error"
-	MsgBadRequest       = "Bad request"
-	MsgNotFound         = "Not found"
+	tokenTypeBearer     = "Bearer"
 	readTimeoutSeconds  = 2
 	writeTimeoutSeconds = 5
+	selfSignedValidFor  = 24 * time.Hour
 )
@@ -1,15 +1,60 @@
 package rest
 
 import (
+	"context"
+	"net/http"
+	"time"
+
 	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/go-devs-ua/octagon/pkg/ratelimit"
 )
 
 //go:generate mockgen -source=./contracts.go -destination=./mock_usecase_test.go -package=rest
 
 // UserUsecase represents User use-case layer.
 type UserUsecase interface {
-	SignUp(entities.User) (string, error)
-	GetAll(entities.QueryParams) ([]entities.User, error)
-	GetByID(id string) (*entities.User, error)
-	Delete(entities.User) error
+	SignUp(context.Context, entities.User) (string, error)
+	GetAll(context.Context, entities.QueryParams) ([]entities.User, error)
+	Count(context.Context, entities.Filter) (int64, error)
+	GetByID(ctx context.Context, id string) (*entities.User, error)
+	Update(ctx context.Context, id string, patch entities.UserPatch) (*entities.User, error)
+	Delete(context.Context, entities.User) error
+	Restore(ctx context.Context, id string) (*entities.User, error)
+}
+
+// AuthUsecase represents Auth use-case layer.
+type AuthUsecase interface {
+	Login(ctx context.Context, email, password string) (*entities.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*entities.TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
+	Verify(ctx context.Context, accessToken string) (*entities.Principal, error)
+	ChangePassword(ctx context.Context, userID, current, password string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, resetToken, password string) error
+	RequestVerification(ctx context.Context, email string) error
+	VerifyEmail(ctx context.Context, verificationToken string) error
+}
+
+// RoleUsecase represents Role use-case layer.
+type RoleUsecase interface {
+	List(ctx context.Context) ([]entities.Role, error)
+	Assign(ctx context.Context, userID, role string) error
+	Revoke(ctx context.Context, userID, role string) error
+}
+
+// HealthUsecase represents Health use-case layer.
+type HealthUsecase interface {
+	Ready(ctx context.Context) entities.Health
+	Drain()
+}
+
+// Metrics records served requests and exposes collected metrics.
+type Metrics interface {
+	ObserveRequest(route, method string, status int, duration time.Duration)
+	Handler() http.Handler
+}
+
+// RateLimitStore keeps state of rate limits, e.g. token buckets of clients.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Status, error)
 }
@@ -19,3 +19,51 @@ func NewUserHandler(usecase UserUsecase, logger *lgr.Logger) UserHandler {
 		logger:  logger,
 	}
 }
+
+// AuthHandler is Auth HTTP handler
+// which consist of embedded AuthUsecase interface.
+type AuthHandler struct {
+	usecase AuthUsecase
+	logger  *lgr.Logger
+}
+
+// NewAuthHandler will return a new instance
+// of AuthHandler struct accepting AuthUsecase interface.
+func NewAuthHandler(usecase AuthUsecase, logger *lgr.Logger) AuthHandler {
+	return AuthHandler{
+		usecase: usecase,
+		logger:  logger,
+	}
+}
+
+// RoleHandler is Role HTTP handler
+// which consist of embedded RoleUsecase interface.
+type RoleHandler struct {
+	usecase RoleUsecase
+	logger  *lgr.Logger
+}
+
+// NewRoleHandler will return a new instance
+// of RoleHandler struct accepting RoleUsecase interface.
+func NewRoleHandler(usecase RoleUsecase, logger *lgr.Logger) RoleHandler {
+	return RoleHandler{
+		usecase: usecase,
+		logger:  logger,
+	}
+}
+
+// HealthHandler is Health HTTP handler
+// which consist of embedded HealthUsecase interface.
+type HealthHandler struct {
+	usecase HealthUsecase
+	logger  *lgr.Logger
+}
+
+// NewHealthHandler will return a new instance
+// of HealthHandler struct accepting HealthUsecase interface.
+func NewHealthHandler(usecase HealthUsecase, logger *lgr.Logger) HealthHandler {
+	return HealthHandler{
+		usecase: usecase,
+		logger:  logger,
+	}
+}
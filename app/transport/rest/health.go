@@ -0,0 +1,76 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+)
+
+// List of statuses reported by health endpoints.
+const (
+	statusOK      = "ok"
+	statusFailed  = "failed"
+	statusReady   = "ready"
+	statusUnready = "unready"
+)
+
+// HealthResponse represents result of health check.
+// Checks are reported by readiness check only.
+type HealthResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// CheckResult represents result of single readiness check.
+// Reasons of failures are logged only, so internals are not exposed.
+type CheckResult struct {
+	Status string `json:"status"`
+}
+
+func makeHealthRESTful(health entities.Health) HealthResponse {
+	resp := HealthResponse{Status: statusReady, Checks: make(map[string]CheckResult, len(health.Checks))}
+
+	if !health.Ready() {
+		resp.Status = statusUnready
+	}
+
+	for name, err := range health.Checks {
+		result := CheckResult{Status: statusOK}
+
+		if err != nil {
+			result.Status = statusFailed
+		}
+
+		resp.Checks[name] = result
+	}
+
+	return resp
+}
+
+// Live will handle liveness check.
+// It does not touch any dependency, so it only tells that the app serves requests.
+func (hh HealthHandler) Live(w http.ResponseWriter, req *http.Request) {
+	logger := hh.logger.Ctx(req.Context())
+
+	WriteJSONResponse(w, http.StatusOK, HealthResponse{Status: statusOK}, logger)
+}
+
+// Ready will handle readiness check running all registered checks.
+func (hh HealthHandler) Ready(w http.ResponseWriter, req *http.Request) {
+	logger := hh.logger.Ctx(req.Context())
+
+	health := hh.usecase.Ready(req.Context())
+	if !health.Ready() {
+		for name, err := range health.Checks {
+			if err != nil {
+				logger.Warnw("Readiness check failed.", "check", name, "error", err.Error())
+			}
+		}
+
+		WriteJSONResponse(w, http.StatusServiceUnavailable, makeHealthRESTful(health), logger)
+
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, makeHealthRESTful(health), logger)
+}
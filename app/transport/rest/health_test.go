@@ -0,0 +1,96 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/go-devs-ua/octagon/lgr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandler_Live(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hh := HealthHandler{
+		usecase: NewMockHealthUsecase(ctrl),
+		logger:  logger,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	resp := httptest.NewRecorder()
+	hh.Live(resp, req)
+
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.JSONEq(t, `{"status":"ok"}`, resp.Body.String())
+}
+
+func TestHealthHandler_Ready(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	tests := map[string]struct {
+		health          entities.Health
+		expStatusCode   int
+		expResponseBody string
+	}{
+		"ready": {
+			health:          entities.Health{Checks: map[string]error{"database": nil, "migrations": nil}},
+			expStatusCode:   http.StatusOK,
+			expResponseBody: `{"status":"ready","checks":{"database":{"status":"ok"},"migrations":{"status":"ok"}}}`,
+		},
+		"no_checks": {
+			health:          entities.Health{},
+			expStatusCode:   http.StatusOK,
+			expResponseBody: `{"status":"ready"}`,
+		},
+		"failed_check": {
+			health: entities.Health{Checks: map[string]error{
+				"database":   errors.New("ping to database failed: connection refused"),
+				"migrations": nil,
+			}},
+			expStatusCode: http.StatusServiceUnavailable,
+			expResponseBody: `{"status":"unready","checks":{` +
+				`"database":{"status":"failed"},` +
+				`"migrations":{"status":"ok"}}}`,
+		},
+		"shutting_down": {
+			health:          entities.Health{Checks: map[string]error{"shutdown": entities.ErrShuttingDown}},
+			expStatusCode:   http.StatusServiceUnavailable,
+			expResponseBody: `{"status":"unready","checks":{"shutdown":{"status":"failed"}}}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mock := NewMockHealthUsecase(ctrl)
+			mock.EXPECT().Ready(gomock.Any()).Return(tt.health).Times(1)
+
+			hh := HealthHandler{
+				usecase: mock,
+				logger:  logger,
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			resp := httptest.NewRecorder()
+			hh.Ready(resp, req)
+
+			require.Equal(t, tt.expStatusCode, resp.Code)
+			require.JSONEq(t, tt.expResponseBody, resp.Body.String())
+		})
+	}
+}
@@ -1,9 +1,22 @@
 package rest
 
 import (
+	"context"
+	"fmt"
+	"math"
 	"net/http"
+	"net/netip"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/go-devs-ua/octagon/cfg"
 	"github.com/go-devs-ua/octagon/lgr"
+	"github.com/go-devs-ua/octagon/pkg/ratelimit"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 )
 
 // Middleware is simple decorator.
@@ -18,14 +31,403 @@ func WrapMiddlewares(h http.Handler, logger *lgr.Logger, middlewares ...Middlewa
 	return h
 }
 
-// WithLogRequest will log detailed request info.
-func WithLogRequest(h http.Handler, logger *lgr.Logger) http.Handler {
+// headerRequestID carries ID of the request, which is either
+// propagated from the client or generated by the server.
+const (
+	headerRequestID = "X-Request-ID"
+	maxRequestIDLen = 128
+)
+
+// WithAccessLog will assign ID to the request, put logger carrying the ID
+// into the request context and log the request once it is served.
+func WithAccessLog(h http.Handler, logger *lgr.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		logger.Debugw("Request:",
-			"Method", req.Method,
-			"URL", req.URL,
-			"User-Agent", req.UserAgent(),
+		start := time.Now()
+		id := requestID(req)
+		reqLogger := logger.With("request_id", id)
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+		w.Header().Set(headerRequestID, id)
+		ctx := context.WithValue(lgr.NewContext(req.Context(), reqLogger), requestIDCtxKey, id)
+		h.ServeHTTP(sw, req.WithContext(ctx))
+
+		// Query is not logged as it may carry one-time tokens.
+		reqLogger.Infow("Request served",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"status", sw.status,
+			"bytes", sw.size,
+			"duration", time.Since(start),
+			"remote_addr", req.RemoteAddr,
+			"user_agent", req.UserAgent(),
 		)
+	})
+}
+
+// WithRecover will turn panic of the handler into internal server error
+// logging the stack, so the panic does not kill the connection.
+// It has to be chained before WithAccessLog to log the request ID.
+func WithRecover(h http.Handler, logger *lgr.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+
+			// Handler aborted the response deliberately.
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			reqLogger := logger.Ctx(req.Context())
+			reqLogger.Errorw("Recovered from panic.", "panic", rec, "stack", string(debug.Stack()))
+
+			// Response can not be replaced once started, so the connection is aborted silently.
+			if sw.written {
+				panic(http.ErrAbortHandler)
+			}
+
+			writeProblem(w, newProblem(http.StatusInternalServerError, CodeInternal, ""), reqLogger)
+		}()
+
+		h.ServeHTTP(sw, req)
+	})
+}
+
+// requestID propagates ID sent by the client unless it is malformed,
+// otherwise new one is generated.
+func requestID(req *http.Request) string {
+	id := req.Header.Get(headerRequestID)
+	if id == "" || len(id) > maxRequestIDLen {
+		return uuid.NewString()
+	}
+
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return uuid.NewString()
+		}
+	}
+
+	return id
+}
+
+// RequestIDFromContext returns ID assigned to the request by WithAccessLog middleware.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDCtxKey).(string)
+
+	return id, ok
+}
+
+// WithBodyLimit will reject request bodies larger than limit bytes.
+// Declared size is checked upfront, while actual one is enforced
+// by handlers reading the body.
+func WithBodyLimit(limit int64) Middleware {
+	return func(h http.Handler, logger *lgr.Logger) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.ContentLength > limit {
+				writeProblem(w, tooLargeProblem(limit), logger.Ctx(req.Context()))
+
+				return
+			}
+
+			req.Body = http.MaxBytesReader(w, req.Body, limit)
+			h.ServeHTTP(w, req)
+		})
+	}
+}
+
+// WithSecurityHeaders will set headers hardening the response against browser attacks.
+// API serves JSON only, so content is not allowed to load anything or to be framed.
+func WithSecurityHeaders(h http.Handler, logger *lgr.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Strict-Transport-Security", "max-age=63072000; includeSubDomains")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'")
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+
 		h.ServeHTTP(w, req)
 	})
 }
+
+// List of headers browsers are allowed to send and read cross-origin.
+const (
+	corsAllowedHeaders = "Authorization, Content-Type, X-Request-ID"
+	corsExposedHeaders = "Link, WWW-Authenticate, X-Request-ID"
+)
+
+// WithCORS will let browsers call the API from configured origins.
+// Preflight requests are answered with methods the path is routed for,
+// so it has to wrap router.
+func WithCORS(opt cfg.CORS, router *mux.Router) Middleware {
+	return func(h http.Handler, logger *lgr.Logger) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			origin := req.Header.Get("Origin")
+			w.Header().Add("Vary", "Origin")
+
+			if origin == "" || !allowedOrigin(opt.AllowedOrigins, origin) {
+				h.ServeHTTP(w, req)
+
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+
+			if req.Method != http.MethodOptions || req.Header.Get("Access-Control-Request-Method") == "" {
+				w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+				h.ServeHTTP(w, req)
+
+				return
+			}
+
+			methods := routedMethods(router, req)
+			if len(methods) == 0 {
+				h.ServeHTTP(w, req)
+
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(opt.MaxAge.Seconds())))
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+func allowedOrigin(allowed []string, origin string) bool {
+	for _, o := range allowed {
+		if o == cfg.AnyOrigin || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// routedMethods returns methods router has routes for at the request path.
+func routedMethods(router *mux.Router, req *http.Request) []string {
+	var methods []string
+
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		var match mux.RouteMatch
+
+		r := req.Clone(req.Context())
+		r.Method = method
+
+		if router.Match(r, &match) && match.MatchErr == nil {
+			methods = append(methods, method)
+		}
+	}
+
+	return methods
+}
+
+// WithRateLimit will let through at most limit requests of each client to the route.
+// Client is the authenticated user or, for anonymous requests, IP address.
+// Failing store does not block requests.
+func WithRateLimit(store RateLimitStore, route string, limit ratelimit.Limit, proxies []netip.Prefix) Middleware {
+	return func(h http.Handler, logger *lgr.Logger) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			reqLogger := logger.Ctx(req.Context())
+			client := clientKey(req, proxies)
+
+			status, err := store.Take(req.Context(), route+" "+client, limit)
+			if err != nil {
+				reqLogger.Errorw("Failed checking rate limit.", "route", route, "error", err.Error())
+				h.ServeHTTP(w, req)
+
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(status.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(status.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(status.Reset)))
+
+			if !status.Allowed {
+				retryAfter := ceilSeconds(status.RetryAfter)
+				detail := fmt.Sprintf("rate limit exceeded, retry in %d seconds", retryAfter)
+
+				reqLogger.Warnw("Rate limit exceeded.", "route", route, "client", client)
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				writeProblem(w, newProblem(http.StatusTooManyRequests, CodeRateLimited, detail), reqLogger)
+
+				return
+			}
+
+			h.ServeHTTP(w, req)
+		})
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// clientKey identifies client requests are limited for.
+func clientKey(req *http.Request, proxies []netip.Prefix) string {
+	if principal, ok := PrincipalFromContext(req.Context()); ok {
+		return "user:" + principal.UserID
+	}
+
+	return "ip:" + clientIP(req, proxies)
+}
+
+// clientIP returns address of the client. Addresses listed in X-Forwarded-For
+// are walked from the nearest one only while they are added by trusted proxies,
+// so the client can not forge its address.
+func clientIP(req *http.Request, proxies []netip.Prefix) string {
+	addrPort, err := netip.ParseAddrPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+
+	ip := addrPort.Addr().Unmap()
+	hops := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+
+	for i := len(hops) - 1; i >= 0 && trustedProxy(proxies, ip); i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+
+		ip = hop.Unmap()
+	}
+
+	return ip.String()
+}
+
+func trustedProxy(proxies []netip.Prefix, ip netip.Addr) bool {
+	for _, prefix := range proxies {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unmatchedRoute labels requests no route was found for,
+// so arbitrary paths do not blow up number of metrics.
+const unmatchedRoute = "unmatched"
+
+// statusWriter remembers status code and size of the response
+// as well as whether the response has been started.
+type statusWriter struct {
+	http.ResponseWriter
+	status  int
+	size    int
+	written bool
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	sw.status = status
+	sw.written = true
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	n, err := sw.ResponseWriter.Write(b)
+	sw.size += n
+	sw.written = true
+
+	return n, err //nolint:wrapcheck // Writer is only decorated.
+}
+
+// WithMetrics will record status and latency of requests by route template.
+// Route is only known once request is matched, so it has to be used by router.
+func WithMetrics(metrics Metrics) Middleware {
+	return func(h http.Handler, logger *lgr.Logger) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+			h.ServeHTTP(sw, req)
+			metrics.ObserveRequest(routeTemplate(req), req.Method, sw.status, time.Since(start))
+		})
+	}
+}
+
+func routeTemplate(req *http.Request) string {
+	route := mux.CurrentRoute(req)
+	if route == nil {
+		return unmatchedRoute
+	}
+
+	tpl, err := route.GetPathTemplate()
+	if err != nil {
+		return unmatchedRoute
+	}
+
+	return tpl
+}
+
+type ctxKey int
+
+const (
+	principalCtxKey ctxKey = iota
+	requestIDCtxKey
+)
+
+// PrincipalFromContext returns authenticated principal stored by WithAuth middleware.
+func PrincipalFromContext(ctx context.Context) (*entities.Principal, bool) {
+	principal, ok := ctx.Value(principalCtxKey).(*entities.Principal)
+
+	return principal, ok
+}
+
+// WithAuth will validate bearer access token and put
+// authenticated principal into the request context.
+func WithAuth(auth AuthUsecase) Middleware {
+	return func(h http.Handler, logger *lgr.Logger) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			reqLogger := logger.Ctx(req.Context())
+
+			scheme, accessToken, found := strings.Cut(req.Header.Get("Authorization"), " ")
+			if !found || !strings.EqualFold(scheme, tokenTypeBearer) || accessToken == "" {
+				writeProblem(w, newProblem(http.StatusUnauthorized, CodeUnauthorized, "missing bearer token"), reqLogger)
+
+				return
+			}
+
+			principal, err := auth.Verify(req.Context(), accessToken)
+			if err != nil {
+				reqLogger.Debugw("Invalid access token.", "error", err.Error())
+				writeError(w, entities.ErrInvalidToken, reqLogger)
+
+				return
+			}
+
+			h.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), principalCtxKey, principal)))
+		})
+	}
+}
+
+// WithPermission will let request through only if authenticated
+// principal was granted given permission. It has to be chained after WithAuth.
+func WithPermission(permission string) Middleware {
+	return func(h http.Handler, logger *lgr.Logger) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			reqLogger := logger.Ctx(req.Context())
+
+			principal, ok := PrincipalFromContext(req.Context())
+			if !ok {
+				writeProblem(w, newProblem(http.StatusUnauthorized, CodeUnauthorized, "authentication required"), reqLogger)
+
+				return
+			}
+
+			if !principal.HasPermission(permission) {
+				reqLogger.Warnw("Permission denied.", "principal", principal.UserID, "permission", permission)
+				writeProblem(w, newProblem(http.StatusForbidden, CodeForbidden, "missing permission "+permission), reqLogger)
+
+				return
+			}
+
+			h.ServeHTTP(w, req)
+		})
+	}
+}
@@ -0,0 +1,654 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/go-devs-ua/octagon/cfg"
+	"github.com/go-devs-ua/octagon/lgr"
+	"github.com/go-devs-ua/octagon/pkg/ratelimit"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAuth(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	tests := map[string]struct {
+		authorization      string
+		usecaseConstructor func(ctrl *gomock.Controller) AuthUsecase
+		expStatusCode      int
+		expPrincipal       *entities.Principal
+	}{
+		"success": {
+			authorization: "Bearer valid",
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				mock := NewMockAuthUsecase(ctrl)
+				mock.EXPECT().Verify(gomock.Any(), "valid").Return(&entities.Principal{UserID: "42"}, nil).Times(1)
+
+				return mock
+			},
+			expStatusCode: http.StatusOK,
+			expPrincipal:  &entities.Principal{UserID: "42"},
+		},
+		"missing_header": {
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				return NewMockAuthUsecase(ctrl)
+			},
+			expStatusCode: http.StatusUnauthorized,
+		},
+		"wrong_scheme": {
+			authorization: "Basic dXNlcjpwYXNz",
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				return NewMockAuthUsecase(ctrl)
+			},
+			expStatusCode: http.StatusUnauthorized,
+		},
+		"invalid_token": {
+			authorization: "Bearer expired",
+			usecaseConstructor: func(ctrl *gomock.Controller) AuthUsecase {
+				mock := NewMockAuthUsecase(ctrl)
+				mock.EXPECT().Verify(gomock.Any(), "expired").Return(nil, entities.ErrInvalidToken).Times(1)
+
+				return mock
+			},
+			expStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var principal *entities.Principal
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				principal, _ = PrincipalFromContext(req.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			resp := httptest.NewRecorder()
+			WithAuth(tt.usecaseConstructor(ctrl))(next, logger).ServeHTTP(resp, req)
+
+			require.Equal(t, tt.expStatusCode, resp.Code)
+			require.Equal(t, tt.expPrincipal, principal)
+
+			if tt.expStatusCode == http.StatusUnauthorized {
+				require.Equal(t, tokenTypeBearer, resp.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestWithPermission(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	tests := map[string]struct {
+		principal     *entities.Principal
+		expStatusCode int
+	}{
+		"granted": {
+			principal:     &entities.Principal{UserID: "42", Permissions: []string{entities.PermUsersList}},
+			expStatusCode: http.StatusOK,
+		},
+		"denied": {
+			principal:     &entities.Principal{UserID: "42", Roles: []string{entities.RoleMember}},
+			expStatusCode: http.StatusForbidden,
+		},
+		"unauthenticated": {
+			expStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})
+
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			if tt.principal != nil {
+				req = req.WithContext(context.WithValue(req.Context(), principalCtxKey, tt.principal))
+			}
+
+			resp := httptest.NewRecorder()
+			WithPermission(entities.PermUsersList)(next, logger).ServeHTTP(resp, req)
+
+			require.Equal(t, tt.expStatusCode, resp.Code)
+		})
+	}
+}
+
+func TestWithMetrics(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	tests := map[string]struct {
+		method        string
+		target        string
+		expRoute      string
+		expStatusCode int
+	}{
+		"matched_route": {
+			method:        http.MethodGet,
+			target:        "/users/42",
+			expRoute:      "/users/{id}",
+			expStatusCode: http.StatusOK,
+		},
+		"handler_status": {
+			method:        http.MethodDelete,
+			target:        "/users/42",
+			expRoute:      "/users/{id}",
+			expStatusCode: http.StatusNoContent,
+		},
+		"not_found": {
+			method:        http.MethodGet,
+			target:        "/unknown/42",
+			expRoute:      unmatchedRoute,
+			expStatusCode: http.StatusNotFound,
+		},
+		"method_not_allowed": {
+			method:        http.MethodPost,
+			target:        "/users/42",
+			expRoute:      unmatchedRoute,
+			expStatusCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mock := NewMockMetrics(ctrl)
+			mock.EXPECT().ObserveRequest(tt.expRoute, tt.method, tt.expStatusCode, gomock.Any()).Times(1)
+
+			router := new(mux.Router)
+			router.Path("/users/{id}").Methods(http.MethodGet).HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})
+			router.Path("/users/{id}").Methods(http.MethodDelete).HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			})
+			instrument(router, mock, logger)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, httptest.NewRequest(tt.method, tt.target, nil))
+
+			require.Equal(t, tt.expStatusCode, resp.Code)
+		})
+	}
+}
+
+func TestWithAccessLog(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	tests := map[string]struct {
+		requestID    string
+		expPropagate bool
+	}{
+		"generated": {
+			expPropagate: false,
+		},
+		"propagated": {
+			requestID:    "4f6c1e2a-upstream",
+			expPropagate: true,
+		},
+		"malformed": {
+			requestID:    "forged\nlog line",
+			expPropagate: false,
+		},
+		"too_long": {
+			requestID:    strings.Repeat("a", maxRequestIDLen+1),
+			expPropagate: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var ctxID string
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				ctxID, _ = RequestIDFromContext(req.Context())
+				require.NotSame(t, logger, logger.Ctx(req.Context()), "request-scoped logger is not in context")
+				w.WriteHeader(http.StatusCreated)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			if tt.requestID != "" {
+				req.Header.Set(headerRequestID, tt.requestID)
+			}
+
+			resp := httptest.NewRecorder()
+			WithAccessLog(next, logger).ServeHTTP(resp, req)
+
+			id := resp.Header().Get(headerRequestID)
+			require.Equal(t, http.StatusCreated, resp.Code)
+			require.NotEmpty(t, id)
+			require.Equal(t, id, ctxID)
+			require.Equal(t, tt.expPropagate, id == tt.requestID)
+		})
+	}
+}
+
+func TestWithRecover(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	tests := map[string]struct {
+		handler         http.HandlerFunc
+		expStatusCode   int
+		expResponseBody string
+		expAbort        bool
+	}{
+		"no_panic": {
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			expStatusCode: http.StatusNoContent,
+		},
+		"panic": {
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				panic("boom")
+			},
+			expStatusCode:   http.StatusInternalServerError,
+			expResponseBody: `{"type":"urn:octagon:problem:internal_error","title":"Internal Server Error","status":500,"code":"internal_error"}`,
+		},
+		"panic_with_error": {
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				panic(entities.ErrNotFound)
+			},
+			expStatusCode:   http.StatusInternalServerError,
+			expResponseBody: `{"type":"urn:octagon:problem:internal_error","title":"Internal Server Error","status":500,"code":"internal_error"}`,
+		},
+		"panic_after_response_started": {
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				panic("boom")
+			},
+			expStatusCode: http.StatusOK,
+			expAbort:      true,
+		},
+		"abort": {
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				panic(http.ErrAbortHandler)
+			},
+			expStatusCode: http.StatusOK,
+			expAbort:      true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			resp := httptest.NewRecorder()
+			serve := func() { WithRecover(tt.handler, logger).ServeHTTP(resp, req) }
+
+			if tt.expAbort {
+				require.PanicsWithValue(t, http.ErrAbortHandler, serve)
+			} else {
+				require.NotPanics(t, serve)
+			}
+
+			require.Equal(t, tt.expStatusCode, resp.Code)
+			if len(tt.expResponseBody) > 0 {
+				require.JSONEq(t, tt.expResponseBody, resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestWithBodyLimit(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	const limit = 32
+
+	tests := map[string]struct {
+		body            string
+		chunked         bool
+		expStatusCode   int
+		expResponseBody string
+	}{
+		"within_limit": {
+			body:          `{"email":"john@email.com"}`,
+			expStatusCode: http.StatusOK,
+		},
+		"declared_too_large": {
+			body:            `{"email":"` + strings.Repeat("a", limit) + `"}`,
+			expStatusCode:   http.StatusRequestEntityTooLarge,
+			expResponseBody: `{"type":"urn:octagon:problem:payload_too_large","title":"Request Entity Too Large","status":413,"code":"payload_too_large","detail":"request body exceeds 32 bytes"}`,
+		},
+		"read_too_large": {
+			body:            `{"email":"` + strings.Repeat("a", limit) + `"}`,
+			chunked:         true,
+			expStatusCode:   http.StatusRequestEntityTooLarge,
+			expResponseBody: `{"type":"urn:octagon:problem:payload_too_large","title":"Request Entity Too Large","status":413,"code":"payload_too_large","detail":"request body exceeds 32 bytes"}`,
+		},
+		"malformed": {
+			body:            `{"email":`,
+			expStatusCode:   http.StatusBadRequest,
+			expResponseBody: `{"type":"urn:octagon:problem:bad_request","title":"Bad Request","status":400,"code":"bad_request","detail":"unexpected EOF"}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				var body map[string]string
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					writeDecodeError(w, err, logger)
+				}
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+
+			resp := httptest.NewRecorder()
+			WithBodyLimit(limit)(next, logger).ServeHTTP(resp, req)
+
+			require.Equal(t, tt.expStatusCode, resp.Code)
+			if len(tt.expResponseBody) > 0 {
+				require.JSONEq(t, tt.expResponseBody, resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestWithSecurityHeaders(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	resp := httptest.NewRecorder()
+	WithSecurityHeaders(http.NotFoundHandler(), logger).ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	require.Equal(t, "nosniff", resp.Header().Get("X-Content-Type-Options"))
+	require.Equal(t, "default-src 'none'; frame-ancestors 'none'", resp.Header().Get("Content-Security-Policy"))
+	require.Contains(t, resp.Header().Get("Strict-Transport-Security"), "max-age=")
+}
+
+func TestWithCORS(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	tests := map[string]struct {
+		allowed       []string
+		method        string
+		target        string
+		origin        string
+		requestMethod string
+		expStatusCode int
+		expOrigin     string
+		expMethods    string
+	}{
+		"same_origin": {
+			allowed:       []string{"https://app.example.com"},
+			method:        http.MethodGet,
+			target:        "/users/42",
+			expStatusCode: http.StatusOK,
+		},
+		"allowed_origin": {
+			allowed:       []string{"https://app.example.com"},
+			method:        http.MethodGet,
+			target:        "/users/42",
+			origin:        "https://app.example.com",
+			expStatusCode: http.StatusOK,
+			expOrigin:     "https://app.example.com",
+		},
+		"disallowed_origin": {
+			allowed:       []string{"https://app.example.com"},
+			method:        http.MethodGet,
+			target:        "/users/42",
+			origin:        "https://evil.example.com",
+			expStatusCode: http.StatusOK,
+		},
+		"any_origin": {
+			allowed:       []string{"*"},
+			method:        http.MethodGet,
+			target:        "/users/42",
+			origin:        "https://evil.example.com",
+			expStatusCode: http.StatusOK,
+			expOrigin:     "https://evil.example.com",
+		},
+		"disabled": {
+			method:        http.MethodGet,
+			target:        "/users/42",
+			origin:        "https://app.example.com",
+			expStatusCode: http.StatusOK,
+		},
+		"preflight": {
+			allowed:       []string{"https://app.example.com"},
+			method:        http.MethodOptions,
+			target:        "/users/42",
+			origin:        "https://app.example.com",
+			requestMethod: http.MethodPatch,
+			expStatusCode: http.StatusNoContent,
+			expOrigin:     "https://app.example.com",
+			expMethods:    "GET, PATCH",
+		},
+		"preflight_unknown_path": {
+			allowed:       []string{"https://app.example.com"},
+			method:        http.MethodOptions,
+			target:        "/unknown",
+			origin:        "https://app.example.com",
+			requestMethod: http.MethodGet,
+			expStatusCode: http.StatusNotFound,
+			expOrigin:     "https://app.example.com",
+		},
+		"preflight_disallowed_origin": {
+			allowed:       []string{"https://app.example.com"},
+			method:        http.MethodOptions,
+			target:        "/users/42",
+			origin:        "https://evil.example.com",
+			requestMethod: http.MethodGet,
+			expStatusCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			router := new(mux.Router)
+			router.Path("/users/{id}").Methods(http.MethodGet).HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})
+			router.Path("/users/{id}").Methods(http.MethodPatch).HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})
+
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+
+			if tt.requestMethod != "" {
+				req.Header.Set("Access-Control-Request-Method", tt.requestMethod)
+			}
+
+			resp := httptest.NewRecorder()
+			opt := cfg.CORS{AllowedOrigins: tt.allowed, MaxAge: time.Minute}
+			WithCORS(opt, router)(router, logger).ServeHTTP(resp, req)
+
+			require.Equal(t, tt.expStatusCode, resp.Code)
+			require.Equal(t, tt.expOrigin, resp.Header().Get("Access-Control-Allow-Origin"))
+			require.Equal(t, tt.expMethods, resp.Header().Get("Access-Control-Allow-Methods"))
+
+			if tt.expMethods != "" {
+				require.Equal(t, "60", resp.Header().Get("Access-Control-Max-Age"))
+			}
+		})
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	limit := ratelimit.Limit{Requests: 5, Period: time.Minute}
+
+	tests := map[string]struct {
+		principal        *entities.Principal
+		storeConstructor func(ctrl *gomock.Controller) RateLimitStore
+		expStatusCode    int
+		expRemaining     string
+		expRetryAfter    string
+		expResponseBody  string
+	}{
+		"allowed": {
+			storeConstructor: func(ctrl *gomock.Controller) RateLimitStore {
+				mock := NewMockRateLimitStore(ctrl)
+				mock.EXPECT().Take(gomock.Any(), "POST /users ip:192.0.2.1", limit).
+					Return(ratelimit.Status{Allowed: true, Limit: 5, Remaining: 4, Reset: 12 * time.Second}, nil).Times(1)
+
+				return mock
+			},
+			expStatusCode: http.StatusOK,
+			expRemaining:  "4",
+		},
+		"authenticated": {
+			principal: &entities.Principal{UserID: "42"},
+			storeConstructor: func(ctrl *gomock.Controller) RateLimitStore {
+				mock := NewMockRateLimitStore(ctrl)
+				mock.EXPECT().Take(gomock.Any(), "POST /users user:42", limit).
+					Return(ratelimit.Status{Allowed: true, Limit: 5, Remaining: 4, Reset: 12 * time.Second}, nil).Times(1)
+
+				return mock
+			},
+			expStatusCode: http.StatusOK,
+			expRemaining:  "4",
+		},
+		"exceeded": {
+			storeConstructor: func(ctrl *gomock.Controller) RateLimitStore {
+				mock := NewMockRateLimitStore(ctrl)
+				mock.EXPECT().Take(gomock.Any(), "POST /users ip:192.0.2.1", limit).
+					Return(ratelimit.Status{Limit: 5, Reset: time.Minute, RetryAfter: 11500 * time.Millisecond}, nil).Times(1)
+
+				return mock
+			},
+			expStatusCode:   http.StatusTooManyRequests,
+			expRemaining:    "0",
+			expRetryAfter:   "12",
+			expResponseBody: `{"type":"urn:octagon:problem:rate_limited","title":"Too Many Requests","status":429,"code":"rate_limited","detail":"rate limit exceeded, retry in 12 seconds"}`,
+		},
+		"store_failure": {
+			storeConstructor: func(ctrl *gomock.Controller) RateLimitStore {
+				mock := NewMockRateLimitStore(ctrl)
+				mock.EXPECT().Take(gomock.Any(), "POST /users ip:192.0.2.1", limit).
+					Return(ratelimit.Status{}, errors.New("store is down")).Times(1)
+
+				return mock
+			},
+			expStatusCode: http.StatusOK,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})
+
+			req := httptest.NewRequest(http.MethodPost, "/users", nil)
+			if tt.principal != nil {
+				req = req.WithContext(context.WithValue(req.Context(), principalCtxKey, tt.principal))
+			}
+
+			resp := httptest.NewRecorder()
+			WithRateLimit(tt.storeConstructor(ctrl), "POST /users", limit, nil)(next, logger).ServeHTTP(resp, req)
+
+			require.Equal(t, tt.expStatusCode, resp.Code)
+			require.Equal(t, tt.expRemaining, resp.Header().Get("RateLimit-Remaining"))
+			require.Equal(t, tt.expRetryAfter, resp.Header().Get("Retry-After"))
+
+			if len(tt.expResponseBody) > 0 {
+				require.JSONEq(t, tt.expResponseBody, resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("::1/128")}
+
+	tests := map[string]struct {
+		remoteAddr   string
+		forwardedFor []string
+		expIP        string
+	}{
+		"direct": {
+			remoteAddr: "192.0.2.1:1234",
+			expIP:      "192.0.2.1",
+		},
+		"untrusted_proxy": {
+			remoteAddr:   "192.0.2.1:1234",
+			forwardedFor: []string{"198.51.100.7"},
+			expIP:        "192.0.2.1",
+		},
+		"trusted_proxy": {
+			remoteAddr:   "10.0.0.2:1234",
+			forwardedFor: []string{"198.51.100.7"},
+			expIP:        "198.51.100.7",
+		},
+		"forged_by_client": {
+			remoteAddr:   "10.0.0.2:1234",
+			forwardedFor: []string{"203.0.113.9, 198.51.100.7"},
+			expIP:        "198.51.100.7",
+		},
+		"proxy_chain": {
+			remoteAddr:   "[::1]:1234",
+			forwardedFor: []string{"198.51.100.7, 10.0.0.3", "10.0.0.4"},
+			expIP:        "198.51.100.7",
+		},
+		"missing_header": {
+			remoteAddr: "10.0.0.2:1234",
+			expIP:      "10.0.0.2",
+		},
+		"malformed_header": {
+			remoteAddr:   "10.0.0.2:1234",
+			forwardedFor: []string{"unknown"},
+			expIP:        "10.0.0.2",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			req.RemoteAddr = tt.remoteAddr
+
+			for _, val := range tt.forwardedFor {
+				req.Header.Add("X-Forwarded-For", val)
+			}
+
+			require.Equal(t, tt.expIP, clientIP(req, proxies))
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package rest
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+)
+
+// Link relations of neighbouring pages.
+const (
+	relNext = "next"
+	relPrev = "prev"
+)
+
+// Meta describes page of the list.
+// Offset is set only when paginating by offset
+// and Total only when count was requested.
+type Meta struct {
+	Total  *int64 `json:"total,omitempty"`
+	Limit  int    `json:"limit"`
+	Offset *int   `json:"offset,omitempty"`
+	Next   string `json:"next,omitempty"`
+	Prev   string `json:"prev,omitempty"`
+}
+
+// makeMeta describes page of users fetched with given params.
+// It returns nil unless count was requested or there are neighbouring pages.
+// Params are expected to be validated.
+func makeMeta(req *http.Request, params entities.QueryParams, users []entities.User, total *int64) *Meta {
+	limit, _ := strconv.Atoi(params.Limit)
+	meta := Meta{Total: total, Limit: limit}
+
+	if params.UsesCursor() {
+		if cursor := params.NextCursor(users); cursor != "" {
+			meta.Next = pageURL(req, "cursor", cursor)
+		}
+
+		return meta.orNil()
+	}
+
+	offset, _ := strconv.Atoi(params.Offset)
+	meta.Offset = &offset
+
+	hasNext := len(users) == limit && limit > 0
+	if total != nil {
+		hasNext = int64(offset+limit) < *total
+	}
+
+	if hasNext {
+		meta.Next = pageURL(req, "offset", strconv.Itoa(offset+limit))
+	}
+
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+
+		meta.Prev = pageURL(req, "offset", strconv.Itoa(prev))
+	}
+
+	return meta.orNil()
+}
+
+// orNil returns nil if meta tells nothing but what was requested.
+func (m Meta) orNil() *Meta {
+	if m.Total == nil && m.Next == "" && m.Prev == "" {
+		return nil
+	}
+
+	return &m
+}
+
+// pageURL returns URL of the request with query parameter replaced.
+// Empty parameters are dropped.
+func pageURL(req *http.Request, key, value string) string {
+	q := req.URL.Query()
+	q.Set(key, value)
+
+	for k := range q {
+		if q.Get(k) == "" {
+			q.Del(k)
+		}
+	}
+
+	return (&url.URL{Path: req.URL.Path, RawQuery: q.Encode()}).String()
+}
+
+// setLinkHeader sets RFC 8288 Link header pointing to neighbouring pages.
+func setLinkHeader(w http.ResponseWriter, meta *Meta) {
+	if meta == nil {
+		return
+	}
+
+	links := make([]string, 0, 2)
+
+	for _, l := range []struct{ url, rel string }{{meta.Next, relNext}, {meta.Prev, relPrev}} {
+		if l.url != "" {
+			links = append(links, "<"+l.url+`>; rel="`+l.rel+`"`)
+		}
+	}
+
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
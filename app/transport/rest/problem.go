@@ -0,0 +1,149 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/go-devs-ua/octagon/lgr"
+)
+
+// Codes of problems detected by transport itself. Problems caused by domain errors
+// are coded by entities.Error. Clients may rely on codes, unlike on titles and details.
+const (
+	CodeBadRequest       = "bad_request"
+	CodeValidationFailed = "validation_failed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeRouteNotFound    = "route_not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodePayloadTooLarge  = "payload_too_large"
+	CodeRateLimited      = "rate_limited"
+	CodeInternal         = "internal_error"
+)
+
+const (
+	contentTypeProblem = "application/problem+json"
+	problemTypePrefix  = "urn:octagon:problem:"
+)
+
+// Problem is error response body
+// described by RFC 7807 (problem details for HTTP APIs).
+type Problem struct {
+	Type   string         `json:"type"`
+	Title  string         `json:"title"`
+	Status int            `json:"status"`
+	Code   string         `json:"code"`
+	Detail string         `json:"detail,omitempty"`
+	Errors []FieldProblem `json:"errors,omitempty"`
+}
+
+// FieldProblem describes single invalid field of the request.
+type FieldProblem struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// newProblem makes Problem titled after the status.
+func newProblem(status int, code, detail string) Problem {
+	return Problem{
+		Type:   problemTypePrefix + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
+
+// statusOf tells which status domain errors of the kind are reported with.
+func statusOf(kind entities.Kind) int {
+	switch kind {
+	case entities.KindNotFound:
+		return http.StatusNotFound
+	case entities.KindConflict:
+		return http.StatusConflict
+	case entities.KindInvalid:
+		return http.StatusBadRequest
+	case entities.KindUnauthorized:
+		return http.StatusUnauthorized
+	case entities.KindForbidden:
+		return http.StatusForbidden
+	case entities.KindInternal:
+		return http.StatusInternalServerError
+	}
+
+	return http.StatusInternalServerError
+}
+
+// problemFor is the single place errors are turned into problems.
+// Only public messages of domain errors are exposed, anything else is internal error.
+func problemFor(err error) Problem {
+	var validationErr *entities.ValidationError
+	if errors.As(err, &validationErr) {
+		return validationProblem(validationErr)
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return tooLargeProblem(maxBytesErr.Limit)
+	}
+
+	var domainErr *entities.Error
+	if errors.As(err, &domainErr) {
+		return newProblem(statusOf(domainErr.Kind), domainErr.Code, domainErr.Message)
+	}
+
+	return newProblem(http.StatusInternalServerError, CodeInternal, "")
+}
+
+func validationProblem(err *entities.ValidationError) Problem {
+	p := newProblem(http.StatusBadRequest, CodeValidationFailed, "request has invalid fields")
+	p.Title = "Validation failed"
+
+	for _, f := range err.Fields {
+		p.Errors = append(p.Errors, FieldProblem{Field: f.Field, Code: f.Code, Message: f.Message})
+	}
+
+	return p
+}
+
+func tooLargeProblem(limit int64) Problem {
+	return newProblem(http.StatusRequestEntityTooLarge, CodePayloadTooLarge, fmt.Sprintf("request body exceeds %d bytes", limit))
+}
+
+// isInternal reports whether err is reported as internal error,
+// so it is worth logging by the handler.
+func isInternal(err error) bool {
+	return problemFor(err).Status >= http.StatusInternalServerError
+}
+
+// writeError writes problem err is mapped to.
+func writeError(w http.ResponseWriter, err error, logger *lgr.Logger) {
+	writeProblem(w, problemFor(err), logger)
+}
+
+// writeProblem writes problem as application/problem+json.
+// Unauthorized responses are challenging client for bearer token.
+func writeProblem(w http.ResponseWriter, p Problem, logger *lgr.Logger) {
+	if p.Status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", tokenTypeBearer)
+	}
+
+	w.Header().Set("Content-Type", contentTypeProblem)
+	WriteJSONResponse(w, p.Status, p, logger)
+}
+
+// writeDecodeError writes response to request which body could not be decoded
+// telling apart bodies cut by WithBodyLimit middleware.
+func writeDecodeError(w http.ResponseWriter, err error, logger *lgr.Logger) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeProblem(w, tooLargeProblem(maxBytesErr.Limit), logger)
+
+		return
+	}
+
+	writeProblem(w, newProblem(http.StatusBadRequest, CodeBadRequest, err.Error()), logger)
+}
@@ -0,0 +1,151 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/go-devs-ua/octagon/lgr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemFor(t *testing.T) {
+	tests := map[string]struct {
+		err        error
+		expProblem Problem
+	}{
+		"wrapped_domain_error": {
+			err: fmt.Errorf("error while updating user: %w", entities.ErrDuplicateEmail),
+			expProblem: Problem{
+				Type:   "urn:octagon:problem:email_taken",
+				Title:  "Conflict",
+				Status: http.StatusConflict,
+				Code:   "email_taken",
+				Detail: "email is already taken",
+			},
+		},
+		"cause_is_not_exposed": {
+			err: entities.ErrConcurrentUpdate.Wrap(errors.New("pq: could not serialize access due to concurrent update")),
+			expProblem: Problem{
+				Type:   "urn:octagon:problem:concurrent_update",
+				Title:  "Conflict",
+				Status: http.StatusConflict,
+				Code:   "concurrent_update",
+				Detail: "resource was changed concurrently, retry the request",
+			},
+		},
+		"translated_domain_error": {
+			err: entities.ErrInvalidCredentials.Wrap(entities.ErrNotFound),
+			expProblem: Problem{
+				Type:   "urn:octagon:problem:invalid_credentials",
+				Title:  "Unauthorized",
+				Status: http.StatusUnauthorized,
+				Code:   "invalid_credentials",
+				Detail: "invalid email or password",
+			},
+		},
+		"token_not_found": {
+			err: fmt.Errorf("error while consuming token: %w", entities.ErrTokenNotFound),
+			expProblem: Problem{
+				Type:   "urn:octagon:problem:token_not_found",
+				Title:  "Not Found",
+				Status: http.StatusNotFound,
+				Code:   "token_not_found",
+				Detail: "token not found or expired",
+			},
+		},
+		"malformed_cursor": {
+			err: func() error {
+				_, err := entities.DecodeCursor("not a cursor")
+
+				return fmt.Errorf("error decoding cursor: %w", err)
+			}(),
+			expProblem: Problem{
+				Type:   "urn:octagon:problem:malformed_cursor",
+				Title:  "Bad Request",
+				Status: http.StatusBadRequest,
+				Code:   "malformed_cursor",
+				Detail: "cursor is malformed or does not match sort",
+			},
+		},
+		"validation_error": {
+			err: &entities.ValidationError{Fields: []entities.FieldError{
+				{Field: "email", Code: entities.CodeRequired, Message: "is required"},
+				{Field: "password", Code: entities.CodeInvalid, Message: "must be 8 to 256 printable characters without spaces"},
+			}},
+			expProblem: Problem{
+				Type:   "urn:octagon:problem:validation_failed",
+				Title:  "Validation failed",
+				Status: http.StatusBadRequest,
+				Code:   CodeValidationFailed,
+				Detail: "request has invalid fields",
+				Errors: []FieldProblem{
+					{Field: "email", Code: entities.CodeRequired, Message: "is required"},
+					{Field: "password", Code: entities.CodeInvalid, Message: "must be 8 to 256 printable characters without spaces"},
+				},
+			},
+		},
+		"body_too_large": {
+			err: fmt.Errorf("error while decoding: %w", &http.MaxBytesError{Limit: 64}),
+			expProblem: Problem{
+				Type:   "urn:octagon:problem:payload_too_large",
+				Title:  "Request Entity Too Large",
+				Status: http.StatusRequestEntityTooLarge,
+				Code:   CodePayloadTooLarge,
+				Detail: "request body exceeds 64 bytes",
+			},
+		},
+		"unknown_error_is_not_exposed": {
+			err: errors.New("pq: connection refused"),
+			expProblem: Problem{
+				Type:   "urn:octagon:problem:internal_error",
+				Title:  "Internal Server Error",
+				Status: http.StatusInternalServerError,
+				Code:   CodeInternal,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.expProblem, problemFor(tt.err))
+		})
+	}
+}
+
+func TestWriteProblem(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.Fatal("cannot initialize logger")
+	}
+
+	tests := map[string]struct {
+		err             error
+		expStatusCode   int
+		expAuthenticate string
+	}{
+		"unauthorized": {
+			err:             entities.ErrInvalidCredentials,
+			expStatusCode:   http.StatusUnauthorized,
+			expAuthenticate: tokenTypeBearer,
+		},
+		"not_found": {
+			err:           entities.ErrRoleNotFound,
+			expStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			writeError(resp, tt.err, logger)
+
+			require.Equal(t, tt.expStatusCode, resp.Code)
+			require.Equal(t, "application/problem+json", resp.Header().Get("Content-Type"))
+			require.Equal(t, tt.expAuthenticate, resp.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
@@ -7,13 +7,6 @@ import (
 	"github.com/go-devs-ua/octagon/lgr"
 )
 
-// Response will wrap message
-// that will be sent in JSON format.
-type Response struct {
-	Message string `json:"message"`
-	Details string `json:"details"`
-}
-
 // Usually, you need to make a rollback mechanism.
 // If there is an error, roll back the changes
 // from the database. But we will not do that now.
@@ -21,8 +14,11 @@ type Response struct {
 // after an error occurs.
 
 // WriteJSONResponse writes JSON response.
+// Content type set beforehand, e.g. by writeProblem, is kept.
 func WriteJSONResponse(w http.ResponseWriter, statusCode int, data any, logger *lgr.Logger) {
-	w.Header().Set("Content-Type", "application/json")
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
 	w.WriteHeader(statusCode)
 
 	if data == nil {
@@ -34,6 +30,6 @@ func WriteJSONResponse(w http.ResponseWriter, statusCode int, data any, logger *
 	}
 
 	if err := json.NewEncoder(w).Encode(data); err != nil {
-		logger.Errorf("Failed encoding to JSON %+v; with status code %d: %+v\n", data, statusCode, err)
+		logger.Errorw("Failed encoding to JSON.", "status", statusCode, "error", err.Error())
 	}
 }
@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// Role represents model of entities.Role
+// specific to transport layer.
+type Role struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// RolesResponse holds on array of Roles are going to be rendered.
+type RolesResponse struct {
+	Results []Role `json:"results"`
+}
+
+func makeRolesRESTful(roleArr []entities.Role) []Role {
+	roles := make([]Role, 0, len(roleArr))
+
+	for _, r := range roleArr {
+		roles = append(roles, Role{
+			Name:        r.Name,
+			Description: r.Description,
+			Permissions: r.Permissions,
+		})
+	}
+
+	return roles
+}
+
+// GetAllRoles retrieves all roles with their permissions.
+func (rh RoleHandler) GetAllRoles(w http.ResponseWriter, req *http.Request) {
+	logger := rh.logger.Ctx(req.Context())
+
+	roles, err := rh.usecase.List(req.Context())
+	if err != nil {
+		if isInternal(err) {
+			logger.Errorw("Internal error while fetching roles.", "error", err.Error())
+		}
+
+		writeError(w, err, logger)
+
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, RolesResponse{Results: makeRolesRESTful(roles)}, logger)
+}
+
+// AssignRole will handle granting role to the user.
+func (rh RoleHandler) AssignRole(w http.ResponseWriter, req *http.Request) {
+	rh.changeRole(w, req, RoleUsecase.Assign)
+}
+
+// RevokeRole will handle taking role away from the user.
+func (rh RoleHandler) RevokeRole(w http.ResponseWriter, req *http.Request) {
+	rh.changeRole(w, req, RoleUsecase.Revoke)
+}
+
+func (rh RoleHandler) changeRole(w http.ResponseWriter, req *http.Request, change func(RoleUsecase, context.Context, string, string) error,
+) {
+	logger := rh.logger.Ctx(req.Context())
+
+	vars := mux.Vars(req)
+	id, role := vars["id"], vars["role"]
+
+	if _, err := uuid.Parse(id); err != nil {
+		logger.Warnw("Invalid UUID", "ID", id)
+		writeProblem(w, newProblem(http.StatusBadRequest, CodeBadRequest, err.Error()), logger)
+
+		return
+	}
+
+	if err := change(rh.usecase, req.Context(), id, role); err != nil {
+		if isInternal(err) {
+			logger.Errorw("Internal error while changing role.", "ID", id, "role", role, "error", err.Error())
+		}
+
+		writeError(w, err, logger)
+
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusNoContent, nil, logger)
+	logger.Infow("User role changed", "ID", id, "role", role, "method", req.Method)
+}
@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/go-devs-ua/octagon/lgr"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleHandler_AssignRole(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	tests := map[string]struct {
+		id                 string
+		role               string
+		usecaseConstructor func(ctrl *gomock.Controller) RoleUsecase
+		expStatusCode      int
+		expResponseBody    string
+	}{
+		"success": {
+			id:   "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc",
+			role: "admin",
+			usecaseConstructor: func(ctrl *gomock.Controller) RoleUsecase {
+				mock := NewMockRoleUsecase(ctrl)
+				mock.EXPECT().Assign(gomock.Any(), "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc", "admin").Return(nil).Times(1)
+
+				return mock
+			},
+			expStatusCode: http.StatusNoContent,
+		},
+		"invalid_uuid": {
+			id:   "dca5947d",
+			role: "admin",
+			usecaseConstructor: func(ctrl *gomock.Controller) RoleUsecase {
+				return nil
+			},
+			expStatusCode:   http.StatusBadRequest,
+			expResponseBody: `{"type":"urn:octagon:problem:bad_request","title":"Bad Request","status":400,"code":"bad_request","detail":"invalid UUID length: 8"}`,
+		},
+		"unknown_role": {
+			id:   "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc",
+			role: "superuser",
+			usecaseConstructor: func(ctrl *gomock.Controller) RoleUsecase {
+				mock := NewMockRoleUsecase(ctrl)
+				mock.EXPECT().Assign(gomock.Any(), "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc", "superuser").Return(entities.ErrRoleNotFound).Times(1)
+
+				return mock
+			},
+			expStatusCode:   http.StatusNotFound,
+			expResponseBody: `{"type":"urn:octagon:problem:role_not_found","title":"Not Found","status":404,"code":"role_not_found","detail":"role not found"}`,
+		},
+		"internal_server_error": {
+			id:   "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc",
+			role: "admin",
+			usecaseConstructor: func(ctrl *gomock.Controller) RoleUsecase {
+				mock := NewMockRoleUsecase(ctrl)
+				mock.EXPECT().Assign(gomock.Any(), "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc", "admin").Return(errors.New("Internal error")).Times(1)
+
+				return mock
+			},
+			expStatusCode:   http.StatusInternalServerError,
+			expResponseBody: `{"type":"urn:octagon:problem:internal_error","title":"Internal Server Error","status":500,"code":"internal_error"}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			rh := RoleHandler{
+				usecase: tt.usecaseConstructor(ctrl),
+				logger:  logger,
+			}
+
+			req := httptest.NewRequest(http.MethodPut, "*", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id, "role": tt.role})
+
+			resp := httptest.NewRecorder()
+			rh.AssignRole(resp, req)
+
+			require.Equal(t, tt.expStatusCode, resp.Code)
+			if len(tt.expResponseBody) > 0 {
+				require.JSONEq(t, tt.expResponseBody, resp.Body.String())
+			}
+		})
+	}
+}
@@ -1,51 +1,309 @@
 package rest
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
+	"github.com/go-devs-ua/octagon/app/entities"
 	"github.com/go-devs-ua/octagon/cfg"
 	"github.com/go-devs-ua/octagon/lgr"
+	"github.com/go-devs-ua/octagon/pkg/cert"
+	"github.com/go-devs-ua/octagon/pkg/ratelimit"
 	"github.com/gorilla/mux"
 )
 
 // Server is simple server.
-type Server struct{ *http.Server }
+// On shutdown it calls drain to fail readiness checks, keeps serving
+// for drainDelay, so load balancers stop routing traffic to it, then stops
+// accepting connections and waits for in-flight requests for at most shutdownTimeout.
+// It is served over TLS when tlsOpt is enabled.
+type Server struct {
+	*http.Server
+	drain           func()
+	drainDelay      time.Duration
+	shutdownTimeout time.Duration
+	tlsOpt          cfg.TLS
+	logger          *lgr.Logger
+}
 
 type Handlers struct {
-	UserHandler UserHandler
+	UserHandler   UserHandler
+	AuthHandler   AuthHandler
+	RoleHandler   RoleHandler
+	HealthHandler HealthHandler
+	Metrics       Metrics
+	RateLimits    RateLimitStore
 }
 
 // NewServer will initialize the server.
 func NewServer(opt cfg.Options, handlers Handlers, logger *lgr.Logger) *Server {
 	router := new(mux.Router)
+	r := routes{
+		router:     router,
+		auth:       WithAuth(handlers.AuthHandler.usecase),
+		rateLimit:  opt.RateLimit,
+		store:      handlers.RateLimits,
+		registered: make(map[string]bool),
+		logger:     logger,
+	}
 
-	attachUserEndpoints(router, handlers)
-	handler := WrapMiddlewares(router, logger, WithLogRequest)
+	attachUserEndpoints(r, handlers)
+	attachAuthEndpoints(r, handlers)
+	attachRoleEndpoints(r, handlers)
+	attachHealthEndpoints(r, handlers)
+	attachMetricsEndpoints(r, handlers)
 
-	return &Server{
+	for route := range opt.RateLimit.Routes {
+		if !r.registered[route] {
+			logger.Warnf("Rate limit is configured for unknown route %q", route)
+		}
+	}
+	instrument(router, handlers.Metrics, logger)
+	handler := WrapMiddlewares(router, logger,
+		WithBodyLimit(opt.Server.MaxBodyBytes),
+		WithCORS(opt.Server.CORS, router),
+		WithSecurityHeaders,
+		WithRecover,
+		WithAccessLog,
+	)
+
+	srv := &Server{
 		Server: &http.Server{
 			Addr:         opt.Server.Host + ":" + opt.Server.Port,
 			Handler:      handler,
 			ReadTimeout:  readTimeoutSeconds * time.Second,
 			WriteTimeout: writeTimeoutSeconds * time.Second,
 		},
+		drain:           handlers.HealthHandler.usecase.Drain,
+		drainDelay:      opt.Server.DrainDelay,
+		shutdownTimeout: opt.Server.ShutdownTimeout,
+		tlsOpt:          opt.Server.TLS,
+		logger:          logger,
+	}
+
+	// Non-nil map stops server from negotiating HTTP/2 over TLS.
+	if !opt.Server.TLS.HTTP2 {
+		srv.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	}
+
+	return srv
+}
+
+// Run will run our server until ctx is done and then shut it down gracefully.
+// It returns nil once all in-flight requests are drained.
+func (srv *Server) Run(ctx context.Context) error {
+	if srv.tlsOpt.Enabled() {
+		tlsConfig, err := srv.tlsConfig(ctx)
+		if err != nil {
+			return err
+		}
+
+		srv.TLSConfig = tlsConfig
+	}
+
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %w", srv.Addr, err)
+	}
+
+	return srv.serve(ctx, ln)
+}
+
+// tlsConfig provides server with certificate. Certificate loaded from files
+// is swapped once they change on disk, so connections being served are not affected
+// and new ones get new certificate. Files are watched until ctx is done.
+func (srv *Server) tlsConfig(ctx context.Context) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: srv.tlsOpt.MinVersion} //nolint:gosec // Minimal version is validated by cfg.
+
+	if srv.tlsOpt.SelfSigned {
+		hosts := []string{"localhost", "127.0.0.1", "::1"}
+		if host, _, err := net.SplitHostPort(srv.Addr); err == nil && host != "" {
+			hosts = append(hosts, host)
+		}
+
+		certificate, err := cert.SelfSigned(hosts, selfSignedValidFor)
+		if err != nil {
+			return nil, fmt.Errorf("error generating self-signed certificate: %w", err)
+		}
+
+		srv.logger.Warnf("Server uses self-signed TLS certificate, which is meant for development only")
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+
+		return tlsConfig, nil
+	}
+
+	reloader, err := cert.NewReloader(srv.tlsOpt.CertFile, srv.tlsOpt.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error loading TLS certificate: %w", err)
+	}
+
+	if srv.tlsOpt.ReloadInterval > 0 {
+		go reloader.Watch(ctx, srv.tlsOpt.ReloadInterval, func(err error) {
+			if err != nil {
+				srv.logger.Errorf("Error reloading TLS certificate, previous one is kept: %v", err)
+
+				return
+			}
+
+			srv.logger.Infof("TLS certificate reloaded")
+		})
 	}
+
+	tlsConfig.GetCertificate = reloader.GetCertificate
+
+	return tlsConfig, nil
 }
 
-// Run will run our server.
-func (srv *Server) Run() error {
-	if err := srv.ListenAndServe(); err != nil {
+// serve serves connections accepted by ln over TLS when TLSConfig is set.
+func (srv *Server) serve(ctx context.Context, ln net.Listener) error {
+	errs := make(chan error, 1)
+
+	go func() {
+		if srv.TLSConfig != nil {
+			errs <- srv.ServeTLS(ln, "", "")
+
+			return
+		}
+
+		errs <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errs:
 		return fmt.Errorf("error loading the server: %w", err)
+	case <-ctx.Done():
+	}
+
+	// Readiness fails while server still serves,
+	// so no new traffic is routed to it before it stops.
+	if srv.drain != nil {
+		srv.drain()
+	}
+
+	if srv.drainDelay > 0 {
+		srv.logger.Infof("Server is not ready anymore, stopping in %v", srv.drainDelay)
+
+		select {
+		case err := <-errs:
+			return fmt.Errorf("error loading the server: %w", err)
+		case <-time.After(srv.drainDelay):
+		}
 	}
 
+	srv.logger.Infof("Server is shutting down, draining requests for at most %v", srv.shutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), srv.shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		_ = srv.Close()
+
+		return fmt.Errorf("error draining requests: %w", err)
+	}
+
+	srv.logger.Infof("Server stopped")
+
 	return nil
 }
 
-func attachUserEndpoints(router *mux.Router, handlers Handlers) {
-	router.Path("/users").Methods(http.MethodPost).HandlerFunc(handlers.UserHandler.CreateUser)
-	router.Path("/users").Methods(http.MethodGet).HandlerFunc(handlers.UserHandler.GetAllUsers)
-	router.Path("/users/{id}").Methods(http.MethodGet).HandlerFunc(handlers.UserHandler.GetUserByID)
-	router.Path("/users").Methods(http.MethodDelete).HandlerFunc(handlers.UserHandler.DeleteUser)
+// instrument records metrics of matched routes as well as
+// of requests router could not find a route for.
+func instrument(router *mux.Router, metrics Metrics, logger *lgr.Logger) {
+	mw := WithMetrics(metrics)
+
+	router.Use(func(h http.Handler) http.Handler { return mw(h, logger) })
+	router.NotFoundHandler = mw(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeProblem(w, newProblem(http.StatusNotFound, CodeRouteNotFound, ""), logger.Ctx(req.Context()))
+	}), logger)
+	router.MethodNotAllowedHandler = mw(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeProblem(w, newProblem(http.StatusMethodNotAllowed, CodeMethodNotAllowed, ""), logger.Ctx(req.Context()))
+	}), logger)
+}
+
+// routes binds endpoints to router marking each of them
+// as public, protected by authentication or permitted
+// only to principals granted with specific permission.
+// All of them but operational ones are rate limited.
+type routes struct {
+	router     *mux.Router
+	auth       Middleware
+	rateLimit  cfg.RateLimit
+	store      RateLimitStore
+	registered map[string]bool
+	logger     *lgr.Logger
+}
+
+// operational endpoints serve probes and scrapers, so they are never rate limited.
+func (r routes) operational(path, method string, h http.HandlerFunc) {
+	r.router.Path(path).Methods(method).Handler(h)
+}
+
+func (r routes) public(path, method string, h http.HandlerFunc) {
+	r.router.Path(path).Methods(method).Handler(r.limited(path, method, h))
+}
+
+func (r routes) protected(path, method string, h http.HandlerFunc) {
+	r.router.Path(path).Methods(method).Handler(r.auth(r.limited(path, method, h), r.logger))
+}
+
+func (r routes) permitted(path, method, permission string, h http.HandlerFunc) {
+	r.router.Path(path).Methods(method).Handler(r.auth(WithPermission(permission)(r.limited(path, method, h), r.logger), r.logger))
+}
+
+// limited applies rate limit configured for the route or default one.
+// Protected routes are limited after authentication, so authenticated users are limited by ID.
+func (r routes) limited(path, method string, h http.Handler) http.Handler {
+	route := method + " " + path
+	r.registered[route] = true
+
+	rate, ok := r.rateLimit.Routes[route]
+	if !ok {
+		rate = r.rateLimit.Default
+	}
+
+	limit := ratelimit.Limit{Requests: rate.Requests, Period: rate.Period}
+	if limit.Unlimited() {
+		return h
+	}
+
+	return WithRateLimit(r.store, route, limit, r.rateLimit.TrustedProxies)(h, r.logger)
+}
+
+func attachUserEndpoints(r routes, handlers Handlers) {
+	r.public("/users", http.MethodPost, handlers.UserHandler.CreateUser)
+	r.permitted("/users", http.MethodGet, entities.PermUsersList, handlers.UserHandler.GetAllUsers)
+	r.protected("/users/{id}", http.MethodGet, handlers.UserHandler.GetUserByID)
+	r.protected("/users/{id}", http.MethodPatch, handlers.UserHandler.UpdateUser)
+	r.protected("/users", http.MethodDelete, handlers.UserHandler.DeleteUser)
+	r.permitted("/users/{id}/restore", http.MethodPost, entities.PermUsersManage, handlers.UserHandler.RestoreUser)
+}
+
+func attachAuthEndpoints(r routes, handlers Handlers) {
+	r.public("/auth/login", http.MethodPost, handlers.AuthHandler.Login)
+	r.public("/auth/refresh", http.MethodPost, handlers.AuthHandler.Refresh)
+	r.public("/auth/logout", http.MethodPost, handlers.AuthHandler.Logout)
+	r.public("/auth/password-reset", http.MethodPost, handlers.AuthHandler.RequestPasswordReset)
+	r.public("/auth/password-reset/confirm", http.MethodPost, handlers.AuthHandler.ConfirmPasswordReset)
+	r.public("/auth/verify", http.MethodGet, handlers.AuthHandler.VerifyEmail)
+	r.public("/auth/verify", http.MethodPost, handlers.AuthHandler.RequestVerification)
+	r.protected("/users/{id}/password", http.MethodPost, handlers.AuthHandler.ChangePassword)
+}
+
+func attachRoleEndpoints(r routes, handlers Handlers) {
+	r.permitted("/roles", http.MethodGet, entities.PermRolesManage, handlers.RoleHandler.GetAllRoles)
+	r.permitted("/users/{id}/roles/{role}", http.MethodPut, entities.PermRolesManage, handlers.RoleHandler.AssignRole)
+	r.permitted("/users/{id}/roles/{role}", http.MethodDelete, entities.PermRolesManage, handlers.RoleHandler.RevokeRole)
+}
+
+func attachHealthEndpoints(r routes, handlers Handlers) {
+	r.operational("/healthz", http.MethodGet, handlers.HealthHandler.Live)
+	r.operational("/readyz", http.MethodGet, handlers.HealthHandler.Ready)
+}
+
+func attachMetricsEndpoints(r routes, handlers Handlers) {
+	r.operational("/metrics", http.MethodGet, handlers.Metrics.Handler().ServeHTTP)
 }
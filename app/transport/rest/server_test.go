@@ -0,0 +1,330 @@
+package rest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/usecase"
+	"github.com/go-devs-ua/octagon/cfg"
+	"github.com/go-devs-ua/octagon/lgr"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+// startServer serves handler in background and returns its address
+// along with channel receiving result of serve.
+func startServer(ctx context.Context, t *testing.T, handler http.Handler, shutdownTimeout time.Duration) (string, <-chan error) {
+	t.Helper()
+
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &Server{
+		Server:          &http.Server{Handler: handler, ReadHeaderTimeout: time.Second},
+		shutdownTimeout: shutdownTimeout,
+		logger:          logger,
+	}
+	done := make(chan error, 1)
+
+	go func() {
+		done <- srv.serve(ctx, ln)
+	}()
+
+	return "http://" + ln.Addr().String(), done
+}
+
+// slowHandler responds only after release is closed
+// and closes started once request is received.
+func slowHandler(started, release chan struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, "done")
+	})
+}
+
+func TestServer_Run(t *testing.T) {
+	t.Run("drains_in_flight_requests", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		ctx, cancel := context.WithCancel(context.Background())
+		addr, done := startServer(ctx, t, slowHandler(started, release), time.Second)
+
+		responses := make(chan *http.Response, 1)
+
+		go func() {
+			resp, err := http.Get(addr) //nolint:noctx // Request is cancelled by server shutdown.
+			if err != nil {
+				close(responses)
+
+				return
+			}
+
+			responses <- resp
+		}()
+
+		<-started
+		cancel()
+
+		select {
+		case err := <-done:
+			require.FailNow(t, "server stopped before in-flight request was served", "error: %v", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(release)
+
+		resp, ok := <-responses
+		require.True(t, ok, "in-flight request failed")
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "done", string(body))
+
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			require.FailNow(t, "server did not stop after requests were drained")
+		}
+
+		_, err = http.Get(addr) //nolint:noctx,bodyclose // Connection is expected to be refused.
+		require.Error(t, err)
+	})
+
+	t.Run("gives_up_after_shutdown_timeout", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		defer close(release)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		addr, done := startServer(ctx, t, slowHandler(started, release), 20*time.Millisecond)
+
+		go func() {
+			resp, err := http.Get(addr) //nolint:noctx // Request is cut by server shutdown.
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+
+		<-started
+		cancel()
+
+		select {
+		case err := <-done:
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+		case <-time.After(time.Second):
+			require.FailNow(t, "server did not stop after shutdown timeout")
+		}
+	})
+
+	t.Run("stops_idle_server", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		_, done := startServer(ctx, t, http.NotFoundHandler(), time.Second)
+
+		cancel()
+
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			require.FailNow(t, "server did not stop after context was cancelled")
+		}
+	})
+}
+
+func TestServer_Drain(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	health := usecase.NewHealth(time.Second)
+	hh := NewHealthHandler(health, logger)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &Server{
+		Server:          &http.Server{Handler: http.HandlerFunc(hh.Ready), ReadHeaderTimeout: time.Second},
+		drain:           health.Drain,
+		drainDelay:      200 * time.Millisecond,
+		shutdownTimeout: time.Second,
+		logger:          logger,
+	}
+	addr := "http://" + ln.Addr().String()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- srv.serve(ctx, ln)
+	}()
+
+	readyz := func() int {
+		resp, err := http.Get(addr) //nolint:noctx // Server is stopped by the test.
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		return resp.StatusCode
+	}
+
+	require.Equal(t, http.StatusOK, readyz())
+
+	cancel()
+
+	require.Eventually(t, func() bool { return readyz() == http.StatusServiceUnavailable }, 150*time.Millisecond, 10*time.Millisecond,
+		"readiness has to fail while server still serves")
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.FailNow(t, "server did not stop after drain delay")
+	}
+}
+
+func TestServer_Recover(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	router := new(mux.Router)
+	router.Path("/panic").HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		panic("boom")
+	})
+	router.Path("/ok").HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = io.WriteString(w, "done")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addr, _ := startServer(ctx, t, WrapMiddlewares(router, logger, WithRecover, WithAccessLog), time.Second)
+
+	const internalErrBody = `{"type":"urn:octagon:problem:internal_error","title":"Internal Server Error","status":500,"code":"internal_error"}`
+
+	// Requests are repeated to prove that panic does not break the server.
+	for _, tt := range []struct {
+		path            string
+		expStatusCode   int
+		expResponseBody string
+	}{
+		{path: "/panic", expStatusCode: http.StatusInternalServerError, expResponseBody: internalErrBody},
+		{path: "/ok", expStatusCode: http.StatusOK, expResponseBody: "done"},
+		{path: "/panic", expStatusCode: http.StatusInternalServerError, expResponseBody: internalErrBody},
+		{path: "/ok", expStatusCode: http.StatusOK, expResponseBody: "done"},
+	} {
+		resp, err := http.Get(addr + tt.path) //nolint:noctx // Server is stopped by the test.
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, tt.expStatusCode, resp.StatusCode)
+		require.Equal(t, tt.expResponseBody, strings.TrimSpace(string(body)))
+		require.NotEmpty(t, resp.Header.Get(headerRequestID))
+	}
+}
+
+func TestServer_TLS(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	for name, tt := range map[string]struct {
+		http2         bool
+		expProtoMajor int
+	}{
+		"negotiates_http2": {http2: true, expProtoMajor: 2},
+		"http2_disabled":   {http2: false, expProtoMajor: 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			health := NewMockHealthUsecase(ctrl)
+			health.EXPECT().Drain().AnyTimes()
+
+			metrics := NewMockMetrics(ctrl)
+			metrics.EXPECT().Handler().Return(http.NotFoundHandler()).AnyTimes()
+			metrics.EXPECT().ObserveRequest(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+
+			host, port, err := net.SplitHostPort(ln.Addr().String())
+			require.NoError(t, err)
+
+			var opt cfg.Options
+			opt.Server = cfg.Server{
+				Host:            host,
+				Port:            port,
+				ShutdownTimeout: time.Second,
+				MaxBodyBytes:    1 << 20,
+				TLS:             cfg.TLS{SelfSigned: true, HTTP2: tt.http2, MinVersion: tls.VersionTLS12},
+			}
+
+			srv := NewServer(opt, Handlers{
+				AuthHandler:   NewAuthHandler(NewMockAuthUsecase(ctrl), logger),
+				HealthHandler: NewHealthHandler(health, logger),
+				Metrics:       metrics,
+			}, logger)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			srv.TLSConfig, err = srv.tlsConfig(ctx)
+			require.NoError(t, err)
+
+			done := make(chan error, 1)
+
+			go func() {
+				done <- srv.serve(ctx, ln)
+			}()
+
+			roots := x509.NewCertPool()
+			roots.AddCert(srv.TLSConfig.Certificates[0].Leaf)
+			client := &http.Client{Transport: &http.Transport{
+				TLSClientConfig:   &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12},
+				ForceAttemptHTTP2: true,
+			}}
+
+			resp, err := client.Get("https://" + ln.Addr().String() + "/healthz") //nolint:noctx // Server is stopped by the test.
+			require.NoError(t, err)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.JSONEq(t, `{"status":"ok"}`, string(body))
+			require.Equal(t, tt.expProtoMajor, resp.ProtoMajor)
+
+			client.CloseIdleConnections()
+			cancel()
+
+			select {
+			case err := <-done:
+				require.NoError(t, err)
+			case <-time.After(time.Second):
+				require.FailNow(t, "server did not stop after context was cancelled")
+			}
+		})
+	}
+}
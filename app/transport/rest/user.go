@@ -3,10 +3,11 @@ package rest
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/go-devs-ua/octagon/app/entities"
-	"github.com/go-devs-ua/octagon/app/globals"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
@@ -20,31 +21,45 @@ type CreateUserResponse struct {
 // User represents model of entities.User
 // specific to transport layer.
 type User struct {
-	ID        string `json:"id"`
-	Email     string `json:"email"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	CreatedAt string `json:"created_at"`
+	ID         string   `json:"id"`
+	Email      string   `json:"email"`
+	FirstName  string   `json:"first_name"`
+	LastName   string   `json:"last_name"`
+	CreatedAt  string   `json:"created_at"`
+	UpdatedAt  string   `json:"updated_at,omitempty"`
+	VerifiedAt string   `json:"verified_at,omitempty"`
+	DeletedAt  string   `json:"deleted_at,omitempty"`
+	Roles      []string `json:"roles,omitempty"`
 }
 
 // UsersResponse holds on array of Users are going to be rendered.
+// NextCursor is set when there may be more users after the page.
+// Meta is set only when count was requested or there are neighbouring pages.
 type UsersResponse struct {
-	Results []User `json:"results"`
+	Results    []User `json:"results"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Meta       *Meta  `json:"meta,omitempty"`
+}
+
+func makeUserRESTful(u entities.User) User {
+	return User{
+		ID:         u.ID,
+		Email:      u.Email,
+		FirstName:  u.FirstName,
+		LastName:   u.LastName,
+		CreatedAt:  u.CreatedAt,
+		UpdatedAt:  u.UpdatedAt,
+		VerifiedAt: u.VerifiedAt,
+		DeletedAt:  u.DeletedAt,
+		Roles:      u.Roles,
+	}
 }
 
 func makeUsersRESTful(userArr []entities.User) []User {
 	users := make([]User, 0, len(userArr))
 
 	for _, u := range userArr {
-		user := User{
-			ID:        u.ID,
-			Email:     u.Email,
-			FirstName: u.FirstName,
-			LastName:  u.LastName,
-			CreatedAt: u.CreatedAt,
-		}
-
-		users = append(users, user)
+		users = append(users, makeUserRESTful(u))
 	}
 
 	return users
@@ -52,148 +67,350 @@ func makeUsersRESTful(userArr []entities.User) []User {
 
 // CreateUser will handle user creation.
 func (uh UserHandler) CreateUser(w http.ResponseWriter, req *http.Request) {
+	logger := uh.logger.Ctx(req.Context())
+
 	var user entities.User
 
 	if err := json.NewDecoder(req.Body).Decode(&user); err != nil {
-		WriteJSONResponse(w, http.StatusBadRequest, Response{Message: MsgBadRequest, Details: err.Error()}, uh.logger)
-		uh.logger.Errorf("Failed decoding JSON from request %+v: %+v", req, err)
+		writeDecodeError(w, err, logger)
+		logger.Errorw("Failed decoding JSON from request.", "method", req.Method, "path", req.URL.Path, "error", err.Error())
 
 		return
 	}
 
 	defer func() {
 		if err := req.Body.Close(); err != nil {
-			uh.logger.Warnf("Failed closing request %+v: %+v", req, err)
+			logger.Warnw("Failed closing request.", "method", req.Method, "path", req.URL.Path, "error", err.Error())
 		}
 	}()
 
 	if err := user.Validate(); err != nil {
-		WriteJSONResponse(w, http.StatusBadRequest, Response{Message: MsgBadRequest, Details: err.Error()}, uh.logger)
-		uh.logger.Errorf("Failed validating user: %+v", err)
+		writeError(w, err, logger)
+		logger.Errorf("Failed validating user: %+v", err)
 
 		return
 	}
 
-	id, err := uh.usecase.SignUp(user)
+	id, err := uh.usecase.SignUp(req.Context(), user)
 	if err != nil {
-		uh.logger.Errorf("Failed creating user: %+v", err)
-
-		if errors.Is(err, globals.ErrDuplicateEmail) {
-			WriteJSONResponse(w, http.StatusConflict, Response{Message: MsgBadRequest, Details: err.Error()}, uh.logger)
-
-			return
+		if isInternal(err) {
+			logger.Errorf("Failed creating user: %+v", err)
 		}
 
-		WriteJSONResponse(w, http.StatusInternalServerError, Response{Message: MsgInternalSeverErr}, uh.logger)
+		writeError(w, err, logger)
 
 		return
 	}
 
-	WriteJSONResponse(w, http.StatusCreated, CreateUserResponse{ID: id}, uh.logger)
-	uh.logger.Debugw("User successfully created", "ID", id)
+	WriteJSONResponse(w, http.StatusCreated, CreateUserResponse{ID: id}, logger)
+	logger.Debugw("User successfully created", "ID", id)
 }
 
 // GetUserByID will handle user search.
 func (uh UserHandler) GetUserByID(w http.ResponseWriter, req *http.Request) {
+	logger := uh.logger.Ctx(req.Context())
+
 	id := mux.Vars(req)["id"]
 
 	if _, err := uuid.Parse(id); err != nil {
-		uh.logger.Warnw("Invalid UUID", "ID", id)
-		WriteJSONResponse(w, http.StatusBadRequest, Response{Message: MsgBadRequest, Details: err.Error()}, uh.logger)
+		logger.Warnw("Invalid UUID", "ID", id)
+		writeProblem(w, newProblem(http.StatusBadRequest, CodeBadRequest, err.Error()), logger)
 
 		return
 	}
 
-	user, err := uh.usecase.GetByID(id)
-	if err != nil {
-		if errors.Is(err, globals.ErrNotFound) {
-			uh.logger.Debugw("No user found.", "ID", id)
-			WriteJSONResponse(w, http.StatusNotFound, Response{Message: MsgNotFound, Details: err.Error()}, uh.logger)
+	if !uh.authorize(w, req, id, entities.Principal.CanView) {
+		return
+	}
 
-			return
+	user, err := uh.usecase.GetByID(req.Context(), id)
+	if err != nil {
+		if isInternal(err) {
+			logger.Errorw("Internal error while searching user.", "ID", id, "error", err.Error())
 		}
 
-		uh.logger.Errorw("Internal error while searching user.", "ID", id, "error", err.Error())
-		WriteJSONResponse(w, http.StatusInternalServerError, Response{Message: MsgInternalSeverErr}, uh.logger)
+		writeError(w, err, logger)
 
 		return
 	}
 
-	userResp := User{
-		ID:        user.ID,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-	}
-
-	WriteJSONResponse(w, http.StatusOK, userResp, uh.logger)
+	WriteJSONResponse(w, http.StatusOK, makeUserRESTful(*user), logger)
 }
 
 // GetAllUsers retrieves all entities.User by given parameters.
 func (uh UserHandler) GetAllUsers(w http.ResponseWriter, req *http.Request) {
+	logger := uh.logger.Ctx(req.Context())
+
 	var params entities.QueryParams
 
 	params.Offset = req.URL.Query().Get("offset")
 	params.Limit = req.URL.Query().Get("limit")
 	params.Sort = req.URL.Query().Get("sort")
+	params.Cursor = req.URL.Query().Get("cursor")
+	params.Count = req.URL.Query().Get("count")
+	params.Filter = entities.Filter{
+		Email:          req.URL.Query().Get("email"),
+		Name:           req.URL.Query().Get("name"),
+		CreatedAfter:   req.URL.Query().Get("created_after"),
+		CreatedBefore:  req.URL.Query().Get("created_before"),
+		IncludeDeleted: req.URL.Query().Get("include_deleted"),
+		Search:         req.URL.Query().Get("q"),
+	}
 
 	if err := params.Validate(); err != nil {
-		uh.logger.Errorf("Failed validating query: %+v", err)
-		WriteJSONResponse(w, http.StatusBadRequest, Response{Message: MsgBadRequest, Details: err.Error()}, uh.logger)
+		logger.Errorf("Failed validating query: %+v", err)
+		writeError(w, err, logger)
 
 		return
 	}
 
-	users, err := uh.usecase.GetAll(params)
+	if params.Filter.WithDeleted() {
+		if principal, ok := PrincipalFromContext(req.Context()); !ok || !principal.HasPermission(entities.PermUsersManage) {
+			writeProblem(w, newProblem(http.StatusForbidden, CodeForbidden, "missing permission "+entities.PermUsersManage), logger)
+
+			return
+		}
+	}
+
+	users, err := uh.usecase.GetAll(req.Context(), params)
 	if err != nil {
-		uh.logger.Errorf("Failed fetching users from repository: %+v", err)
-		WriteJSONResponse(w, http.StatusInternalServerError, Response{Message: MsgInternalSeverErr, Details: "could not fetch users"}, uh.logger)
+		if isInternal(err) {
+			logger.Errorw("Internal error while fetching users.", "error", err.Error())
+		}
+
+		writeError(w, err, logger)
 
 		return
 	}
 
-	WriteJSONResponse(w, http.StatusOK, UsersResponse{Results: makeUsersRESTful(users)}, uh.logger)
+	var total *int64
+
+	if params.WithCount() {
+		n, err := uh.usecase.Count(req.Context(), params.Filter)
+		if err != nil {
+			if isInternal(err) {
+				logger.Errorw("Internal error while counting users.", "error", err.Error())
+			}
+
+			writeError(w, err, logger)
+
+			return
+		}
+
+		total = &n
+	}
+
+	meta := makeMeta(req, params, users, total)
+	setLinkHeader(w, meta)
+
+	WriteJSONResponse(w, http.StatusOK, UsersResponse{
+		Results:    makeUsersRESTful(users),
+		NextCursor: params.NextCursor(users),
+		Meta:       meta,
+	}, logger)
+}
+
+// UpdateUser will handle partial update of user profile
+// described by JSON merge patch (RFC 7396).
+func (uh UserHandler) UpdateUser(w http.ResponseWriter, req *http.Request) {
+	logger := uh.logger.Ctx(req.Context())
+
+	id := mux.Vars(req)["id"]
+
+	if _, err := uuid.Parse(id); err != nil {
+		logger.Warnw("Invalid UUID", "ID", id)
+		writeProblem(w, newProblem(http.StatusBadRequest, CodeBadRequest, err.Error()), logger)
+
+		return
+	}
+
+	if !uh.authorize(w, req, id, entities.Principal.CanActOn) {
+		return
+	}
+
+	patch, err := decodeUserPatch(req.Body)
+	if err != nil {
+		writeDecodeError(w, err, logger)
+		logger.Errorw("Failed decoding patch from request.", "method", req.Method, "path", req.URL.Path, "error", err.Error())
+
+		return
+	}
+
+	defer func() {
+		if err := req.Body.Close(); err != nil {
+			logger.Warnw("Failed closing request.", "method", req.Method, "path", req.URL.Path, "error", err.Error())
+		}
+	}()
+
+	if err := patch.Validate(); err != nil {
+		writeError(w, err, logger)
+		logger.Errorf("Failed validating patch: %+v", err)
+
+		return
+	}
+
+	user, err := uh.usecase.Update(req.Context(), id, patch)
+	if err != nil {
+		if isInternal(err) {
+			logger.Errorw("Internal error while updating user.", "ID", id, "error", err.Error())
+		}
+
+		writeError(w, err, logger)
+
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, makeUserRESTful(*user), logger)
+	logger.Debugw("User successfully updated", "ID", id)
+}
+
+// decodeUserPatch decodes JSON merge patch into entities.UserPatch.
+// Null removes optional last name, while required fields can not be removed.
+func decodeUserPatch(r io.Reader) (entities.UserPatch, error) {
+	var (
+		patch entities.UserPatch
+		raw   map[string]json.RawMessage
+	)
+
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return patch, err
+	}
+
+	for key, val := range raw {
+		var err error
+
+		switch key {
+		case "first_name":
+			patch.FirstName, err = patchString(key, val, false)
+		case "last_name":
+			patch.LastName, err = patchString(key, val, true)
+		case "email":
+			patch.Email, err = patchString(key, val, false)
+		default:
+			err = fmt.Errorf("field %q can not be patched", key)
+		}
+
+		if err != nil {
+			return patch, err
+		}
+	}
+
+	return patch, nil
+}
+
+func patchString(key string, val json.RawMessage, removable bool) (*string, error) {
+	var s *string
+
+	if err := json.Unmarshal(val, &s); err != nil {
+		return nil, fmt.Errorf("field %q has to be a string", key)
+	}
+
+	if s == nil {
+		if !removable {
+			return nil, fmt.Errorf("field %q can not be removed", key)
+		}
+
+		s = new(string)
+	}
+
+	return s, nil
 }
 
 // DeleteUser will handle user creation.
 func (uh UserHandler) DeleteUser(w http.ResponseWriter, req *http.Request) {
+	logger := uh.logger.Ctx(req.Context())
+
 	var user entities.User
 
 	if err := json.NewDecoder(req.Body).Decode(&user); err != nil {
-		WriteJSONResponse(w, http.StatusBadRequest, Response{Message: MsgBadRequest, Details: err.Error()}, uh.logger)
-		uh.logger.Errorf("Failed decoding JSON from request %+v: %+v", req, err)
+		writeDecodeError(w, err, logger)
+		logger.Errorw("Failed decoding JSON from request.", "method", req.Method, "path", req.URL.Path, "error", err.Error())
 
 		return
 	}
 
 	defer func() {
 		if err := req.Body.Close(); err != nil {
-			uh.logger.Warnf("Failed closing request %+v: %+v", req, err)
+			logger.Warnw("Failed closing request.", "method", req.Method, "path", req.URL.Path, "error", err.Error())
 		}
 	}()
 
 	if err := user.ValidateUUID(); err != nil {
-		uh.logger.Warnf("Invalid ID in the request: %s", user)
-		WriteJSONResponse(w, http.StatusBadRequest, Response{Message: MsgBadRequest, Details: err.Error()}, uh.logger)
+		logger.Warnf("Invalid ID in the request: %s", user)
+		writeProblem(w, newProblem(http.StatusBadRequest, CodeBadRequest, err.Error()), logger)
 
 		return
 	}
 
-	if err := uh.usecase.Delete(user); err != nil {
-		if errors.Is(err, globals.ErrNotFound) {
-			uh.logger.Debugw("No user found.", "ID", user.ID)
-			WriteJSONResponse(w, http.StatusNotFound, Response{Message: MsgNotFound, Details: err.Error()}, uh.logger)
+	if !uh.authorize(w, req, user.ID, entities.Principal.CanActOn) {
+		return
+	}
 
-			return
+	if err := uh.usecase.Delete(req.Context(), user); err != nil {
+		if isInternal(err) {
+			logger.Errorw("Internal error while deleting user.", "ID", user.ID, "error", err.Error())
 		}
 
-		uh.logger.Errorw("Internal error while deleting user.", "ID", user.ID, "error", err.Error())
-		WriteJSONResponse(w, http.StatusInternalServerError, Response{Message: MsgInternalSeverErr}, uh.logger)
+		writeError(w, err, logger)
 
 		return
 	}
 
-	WriteJSONResponse(w, http.StatusNoContent, nil, uh.logger)
-	uh.logger.Debugw("User successfully deleted", "ID", user.ID)
+	WriteJSONResponse(w, http.StatusNoContent, nil, logger)
+	logger.Debugw("User successfully deleted", "ID", user.ID)
+}
+
+// RestoreUser will handle undoing deletion of the user within grace period.
+func (uh UserHandler) RestoreUser(w http.ResponseWriter, req *http.Request) {
+	logger := uh.logger.Ctx(req.Context())
+
+	id := mux.Vars(req)["id"]
+
+	if _, err := uuid.Parse(id); err != nil {
+		logger.Warnw("Invalid UUID", "ID", id)
+		writeProblem(w, newProblem(http.StatusBadRequest, CodeBadRequest, err.Error()), logger)
+
+		return
+	}
+
+	user, err := uh.usecase.Restore(req.Context(), id)
+	if err != nil {
+		problem := problemFor(err)
+
+		switch {
+		case errors.Is(err, entities.ErrNotFound):
+			problem.Detail = "no restorable user found"
+		case isInternal(err):
+			logger.Errorw("Internal error while restoring user.", "ID", id, "error", err.Error())
+		}
+
+		writeProblem(w, problem, logger)
+
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, makeUserRESTful(*user), logger)
+	logger.Infow("User restored", "ID", id)
+}
+
+// authorize checks that authenticated principal is allowed by can
+// to access account of the user with given ID.
+func (uh UserHandler) authorize(w http.ResponseWriter, req *http.Request, userID string, can func(entities.Principal, string) bool) bool {
+	logger := uh.logger.Ctx(req.Context())
+
+	principal, ok := PrincipalFromContext(req.Context())
+	if !ok {
+		writeProblem(w, newProblem(http.StatusUnauthorized, CodeUnauthorized, "authentication required"), logger)
+
+		return false
+	}
+
+	if !can(*principal, userID) {
+		logger.Warnw("Forbidden action on foreign account.", "principal", principal.UserID, "ID", userID)
+		writeProblem(w, newProblem(http.StatusForbidden, CodeForbidden, "not allowed to act on this account"), logger)
+
+		return false
+	}
+
+	return true
 }
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -8,7 +9,6 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/go-devs-ua/octagon/app/globals"
 	"github.com/gorilla/mux"
   
 	"github.com/go-devs-ua/octagon/app/entities"
@@ -27,6 +27,7 @@ func TestUserHandler_GetAllUsers(t *testing.T) {
 		params                entities.QueryParams
 		expectedStatusCode    int
 		expectedResponsetBody string
+		expectedLinkHeader    string
 		usecaseBuilder        func(ctrl *gomock.Controller, params entities.QueryParams) UserUsecase
 	}{
 		"succes": {
@@ -37,7 +38,7 @@ func TestUserHandler_GetAllUsers(t *testing.T) {
 			usecaseBuilder: func(ctrl *gomock.Controller, params entities.QueryParams) UserUsecase {
 				mock := NewMockUserUsecase(ctrl)
 
-				mock.EXPECT().GetAll(params).Return(
+				mock.EXPECT().GetAll(gomock.Any(), params).Return(
 					[]entities.User{
 						{
 							ID:        "931add34-1f6d-4c06-b0e8-c37ac1ca614c",
@@ -88,7 +89,7 @@ func TestUserHandler_GetAllUsers(t *testing.T) {
 				return nil
 			},
 			expectedStatusCode:    400,
-			expectedResponsetBody: `{"details":"offset argument has to be a number", "message":"Bad request"}`,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"offset","code":"invalid","message":"must be a number"}]}`,
 		},
 		"extralong-offset": {
 			params: entities.QueryParams{
@@ -99,7 +100,7 @@ func TestUserHandler_GetAllUsers(t *testing.T) {
 				return nil
 			},
 			expectedStatusCode:    400,
-			expectedResponsetBody: `{"details":"offset argument has to be a number", "message":"Bad request"}`,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"offset","code":"invalid","message":"must be a number"}]}`,
 		},
 		"negative-offset": {
 			params: entities.QueryParams{
@@ -110,7 +111,7 @@ func TestUserHandler_GetAllUsers(t *testing.T) {
 				return nil
 			},
 			expectedStatusCode:    400,
-			expectedResponsetBody: `{"details":"offset argument has to be a positive number", "message":"Bad request"}`,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"offset","code":"invalid","message":"must be a positive number"}]}`,
 		},
 		"invalid-Limit": {
 			params: entities.QueryParams{
@@ -121,7 +122,7 @@ func TestUserHandler_GetAllUsers(t *testing.T) {
 				return nil
 			},
 			expectedStatusCode:    400,
-			expectedResponsetBody: `{"details":"limit argument has to be a number", "message":"Bad request"}`,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"limit","code":"invalid","message":"must be a number"}]}`,
 		},
 		"negative-Limit": {
 			params: entities.QueryParams{
@@ -132,7 +133,7 @@ func TestUserHandler_GetAllUsers(t *testing.T) {
 				return nil
 			},
 			expectedStatusCode:    400,
-			expectedResponsetBody: `{"details":"limit argument has to be a positive number", "message":"Bad request"}`,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"limit","code":"invalid","message":"must be a positive number"}]}`,
 		},
 		"extralong-Limit": {
 			params: entities.QueryParams{
@@ -143,7 +144,7 @@ func TestUserHandler_GetAllUsers(t *testing.T) {
 				return nil
 			},
 			expectedStatusCode:    400,
-			expectedResponsetBody: `{"details":"limit argument has to be a number", "message":"Bad request"}`,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"limit","code":"invalid","message":"must be a number"}]}`,
 		},
 		"bad-sorting": {
 			params: entities.QueryParams{
@@ -155,24 +156,246 @@ func TestUserHandler_GetAllUsers(t *testing.T) {
 				return nil
 			},
 			expectedStatusCode:    400,
-			expectedResponsetBody: `{"details": "sort argument '_bad' does not fit list: [first_name last_name created_at ,]", "message": "Bad request"}`,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"sort","code":"invalid","message":"sort key 'first_name_bad' is not one of [first_name last_name created_at]"}]}`,
 		},
-		"internal-server-error": {
+		"repeated-sort-key": {
 			params: entities.QueryParams{
 				Offset: "0",
-				Limit:  "1",
+				Limit:  "5",
+				Sort:   "-created_at,last_name,created_at",
+			},
+			usecaseBuilder: func(ctrl *gomock.Controller, params entities.QueryParams) UserUsecase {
+				return nil
+			},
+			expectedStatusCode:    400,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"sort","code":"invalid","message":"sort key 'created_at' is repeated"}]}`,
+		},
+		"empty-sort-key": {
+			params: entities.QueryParams{
+				Offset: "0",
+				Limit:  "5",
+				Sort:   "last_name,,created_at",
+			},
+			usecaseBuilder: func(ctrl *gomock.Controller, params entities.QueryParams) UserUsecase {
+				return nil
+			},
+			expectedStatusCode:    400,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"sort","code":"invalid","message":"sort key at position 2 is empty"}]}`,
+		},
+		"cursor-first-page": {
+			params: entities.QueryParams{
+				Limit: "1",
+				Sort:  "-created_at",
 			},
 			usecaseBuilder: func(ctrl *gomock.Controller, params entities.QueryParams) UserUsecase {
 				mock := NewMockUserUsecase(ctrl)
-				mock.EXPECT().GetAll(params).Return(nil, errors.New("Internal error"))
+
+				mock.EXPECT().GetAll(gomock.Any(), params).Return(
+					[]entities.User{
+						{
+							ID:        "4fddf9a4-fbd1-4083-98aa-e4d0e584e7bb",
+							FirstName: "John2",
+							LastName:  "Doe",
+							Email:     "john2@examlpe.com",
+							CreatedAt: "2022-11-05T22:28:36.679554Z",
+						},
+					}, nil).Times(1)
 
 				return mock
 			},
-			expectedStatusCode: 500,
+			expectedStatusCode: 200,
 			expectedResponsetBody: `{
-				"message": "Internal server error",
-				"details": "could not fetch users"
+				"results": [
+					{
+						"id": "4fddf9a4-fbd1-4083-98aa-e4d0e584e7bb",
+						"email": "john2@examlpe.com",
+						"first_name": "John2",
+						"last_name": "Doe",
+						"created_at": "2022-11-05T22:28:36.679554Z"
+					}
+				],
+				"next_cursor": "eyJrIjpbIjIwMjItMTEtMDVUMjI6Mjg6MzYuNjc5NTU0WiJdLCJpZCI6IjRmZGRmOWE0LWZiZDEtNDA4My05OGFhLWU0ZDBlNTg0ZTdiYiJ9",
+				"meta": {"limit": 1, "next": "/users?cursor=eyJrIjpbIjIwMjItMTEtMDVUMjI6Mjg6MzYuNjc5NTU0WiJdLCJpZCI6IjRmZGRmOWE0LWZiZDEtNDA4My05OGFhLWU0ZDBlNTg0ZTdiYiJ9&limit=1&sort=-created_at"}
 			}`,
+			expectedLinkHeader: `</users?cursor=eyJrIjpbIjIwMjItMTEtMDVUMjI6Mjg6MzYuNjc5NTU0WiJdLCJpZCI6IjRmZGRmOWE0LWZiZDEtNDA4My05OGFhLWU0ZDBlNTg0ZTdiYiJ9&limit=1&sort=-created_at>; rel="next"`,
+		},
+		"cursor-last-page": {
+			params: entities.QueryParams{
+				Limit:  "5",
+				Sort:   "-created_at",
+				Cursor: "eyJrIjpbIjIwMjItMTEtMDVUMjI6Mjg6MzYuNjc5NTU0WiJdLCJpZCI6IjRmZGRmOWE0LWZiZDEtNDA4My05OGFhLWU0ZDBlNTg0ZTdiYiJ9",
+			},
+			usecaseBuilder: func(ctrl *gomock.Controller, params entities.QueryParams) UserUsecase {
+				mock := NewMockUserUsecase(ctrl)
+				mock.EXPECT().GetAll(gomock.Any(), params).Return(nil, nil).Times(1)
+
+				return mock
+			},
+			expectedStatusCode:    200,
+			expectedResponsetBody: `{"results": []}`,
+		},
+		"malformed-cursor": {
+			params: entities.QueryParams{
+				Limit:  "5",
+				Sort:   "created_at",
+				Cursor: "eyJrIjpbInllc3RlcmRheSJdLCJpZCI6IjRmZGRmOWE0LWZiZDEtNDA4My05OGFhLWU0ZDBlNTg0ZTdiYiJ9",
+			},
+			usecaseBuilder: func(ctrl *gomock.Controller, params entities.QueryParams) UserUsecase {
+				return nil
+			},
+			expectedStatusCode:    400,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"cursor","code":"invalid","message":"is malformed"}]}`,
+		},
+		"cursor-sort-mismatch": {
+			params: entities.QueryParams{
+				Limit:  "5",
+				Cursor: "eyJrIjpbIjIwMjItMTEtMDVUMjI6Mjg6MzYuNjc5NTU0WiJdLCJpZCI6IjRmZGRmOWE0LWZiZDEtNDA4My05OGFhLWU0ZDBlNTg0ZTdiYiJ9",
+			},
+			usecaseBuilder: func(ctrl *gomock.Controller, params entities.QueryParams) UserUsecase {
+				return nil
+			},
+			expectedStatusCode:    400,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"cursor","code":"invalid","message":"does not match sort"}]}`,
+		},
+		"cursor-with-offset": {
+			params: entities.QueryParams{
+				Offset: "5",
+				Limit:  "5",
+				Cursor: "eyJrIjpbIjIwMjItMTEtMDVUMjI6Mjg6MzYuNjc5NTU0WiJdLCJpZCI6IjRmZGRmOWE0LWZiZDEtNDA4My05OGFhLWU0ZDBlNTg0ZTdiYiJ9",
+			},
+			usecaseBuilder: func(ctrl *gomock.Controller, params entities.QueryParams) UserUsecase {
+				return nil
+			},
+			expectedStatusCode:    400,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"cursor","code":"invalid","message":"can not be combined with offset"}]}`,
+		},
+		"filtered": {
+			params: entities.QueryParams{
+				Offset: "0",
+				Limit:  "5",
+				Filter: entities.Filter{
+					Name:         "Jo",
+					CreatedAfter: "2022-11-01T00:00:00Z",
+					Search:       "examlpe",
+				},
+			},
+			usecaseBuilder: func(ctrl *gomock.Controller, params entities.QueryParams) UserUsecase {
+				mock := NewMockUserUsecase(ctrl)
+				mock.EXPECT().GetAll(gomock.Any(), params).Return(nil, nil).Times(1)
+
+				return mock
+			},
+			expectedStatusCode:    200,
+			expectedResponsetBody: `{"results": []}`,
+		},
+		"counted": {
+			params: entities.QueryParams{
+				Offset: "5",
+				Limit:  "5",
+				Count:  "true",
+			},
+			usecaseBuilder: func(ctrl *gomock.Controller, params entities.QueryParams) UserUsecase {
+				mock := NewMockUserUsecase(ctrl)
+				mock.EXPECT().GetAll(gomock.Any(), params).Return(nil, nil).Times(1)
+				mock.EXPECT().Count(gomock.Any(), params.Filter).Return(int64(12), nil).Times(1)
+
+				return mock
+			},
+			expectedStatusCode: 200,
+			expectedResponsetBody: `{"results": [], "meta": {"total": 12, "limit": 5, "offset": 5,
+				"next": "/users?count=true&limit=5&offset=10", "prev": "/users?count=true&limit=5&offset=0"}}`,
+			expectedLinkHeader: `</users?count=true&limit=5&offset=10>; rel="next", </users?count=true&limit=5&offset=0>; rel="prev"`,
+		},
+		"invalid-count": {
+			params: entities.QueryParams{
+				Offset: "0",
+				Limit:  "5",
+				Count:  "maybe",
+			},
+			usecaseBuilder: func(ctrl *gomock.Controller, params entities.QueryParams) UserUsecase {
+				return nil
+			},
+			expectedStatusCode:    400,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"count","code":"invalid","message":"must be a boolean"}]}`,
+		},
+		"invalid-include-deleted": {
+			params: entities.QueryParams{
+				Offset: "0",
+				Limit:  "5",
+				Filter: entities.Filter{IncludeDeleted: "sometimes"},
+			},
+			usecaseBuilder: func(ctrl *gomock.Controller, params entities.QueryParams) UserUsecase {
+				return nil
+			},
+			expectedStatusCode:    400,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"include_deleted","code":"invalid","message":"must be a boolean"}]}`,
+		},
+		"invalid-created-before": {
+			params: entities.QueryParams{
+				Offset: "0",
+				Limit:  "5",
+				Filter: entities.Filter{CreatedBefore: "yesterday"},
+			},
+			usecaseBuilder: func(ctrl *gomock.Controller, params entities.QueryParams) UserUsecase {
+				return nil
+			},
+			expectedStatusCode:    400,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"created_before","code":"invalid","message":"must be RFC 3339 time, e.g. 2006-01-02T15:04:05Z"}]}`,
+		},
+		"invalid-fields": {
+			params: entities.QueryParams{
+				Offset: "-1",
+				Limit:  "bad",
+				Count:  "maybe",
+				Filter: entities.Filter{Email: "not-an-email"},
+			},
+			usecaseBuilder: func(ctrl *gomock.Controller, params entities.QueryParams) UserUsecase {
+				return nil
+			},
+			expectedStatusCode: 400,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[` +
+				`{"field":"limit","code":"invalid","message":"must be a number"},` +
+				`{"field":"email","code":"invalid","message":"must be a valid email address"},` +
+				`{"field":"count","code":"invalid","message":"must be a boolean"},` +
+				`{"field":"offset","code":"invalid","message":"must be a positive number"}]}`,
+		},
+		"empty-created-range": {
+			params: entities.QueryParams{
+				Offset: "0",
+				Limit:  "5",
+				Filter: entities.Filter{CreatedAfter: "2022-11-01T00:00:00Z", CreatedBefore: "2022-10-01T00:00:00Z"},
+			},
+			usecaseBuilder: func(ctrl *gomock.Controller, params entities.QueryParams) UserUsecase {
+				return nil
+			},
+			expectedStatusCode:    400,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,"code":"validation_failed","detail":"request has invalid fields","errors":[{"field":"created_after","code":"invalid","message":"must be earlier than created_before"}]}`,
+		},
+		"include-deleted-without-permission": {
+			params: entities.QueryParams{
+				Offset: "0",
+				Limit:  "5",
+				Filter: entities.Filter{IncludeDeleted: "true"},
+			},
+			usecaseBuilder: func(ctrl *gomock.Controller, params entities.QueryParams) UserUsecase {
+				return nil
+			},
+			expectedStatusCode:    403,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:forbidden","title":"Forbidden","status":403,"code":"forbidden","detail":"missing permission users:manage"}`,
+		},
+		"internal-server-error": {
+			params: entities.QueryParams{
+				Offset: "0",
+				Limit:  "1",
+			},
+			usecaseBuilder: func(ctrl *gomock.Controller, params entities.QueryParams) UserUsecase {
+				mock := NewMockUserUsecase(ctrl)
+				mock.EXPECT().GetAll(gomock.Any(), params).Return(nil, errors.New("Internal error"))
+
+				return mock
+			},
+			expectedStatusCode:    500,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:internal_error","title":"Internal Server Error","status":500,"code":"internal_error"}`,
 		},
 	}
 
@@ -195,6 +418,14 @@ func TestUserHandler_GetAllUsers(t *testing.T) {
 			q.Add("offset", tt.params.Offset)
 			q.Add("limit", tt.params.Limit)
 			q.Add("sort", tt.params.Sort)
+			q.Add("cursor", tt.params.Cursor)
+			q.Add("count", tt.params.Count)
+			q.Add("email", tt.params.Filter.Email)
+			q.Add("name", tt.params.Filter.Name)
+			q.Add("created_after", tt.params.Filter.CreatedAfter)
+			q.Add("created_before", tt.params.Filter.CreatedBefore)
+			q.Add("include_deleted", tt.params.Filter.IncludeDeleted)
+			q.Add("q", tt.params.Filter.Search)
 
 			request.URL.RawQuery = q.Encode()
 
@@ -204,6 +435,7 @@ func TestUserHandler_GetAllUsers(t *testing.T) {
 			// Check results of testing.
 			require.Equal(t, tt.expectedStatusCode, response.Code)
 			require.JSONEq(t, tt.expectedResponsetBody, response.Body.String())
+			require.Equal(t, tt.expectedLinkHeader, response.Header().Get("Link"))
 		})
 	}
 }
@@ -216,16 +448,18 @@ func TestUserHandler_GetUserByID(t *testing.T) {
 
 	tests := map[string]struct {
 		id                    string
+		principal             *entities.Principal
 		usecaseBuilder        func(ctrl *gomock.Controller) UserUsecase
 		expectedStatusCode    int
 		expectedResponsetBody string
 	}{
 		"success": {
-			id: "91e3dcf7-34a6-4646-bd37-383cc949da93",
+			id:        "91e3dcf7-34a6-4646-bd37-383cc949da93",
+			principal: &entities.Principal{UserID: "91e3dcf7-34a6-4646-bd37-383cc949da93"},
 			usecaseBuilder: func(ctrl *gomock.Controller) UserUsecase {
 				mock := NewMockUserUsecase(ctrl)
 
-				mock.EXPECT().GetByID("91e3dcf7-34a6-4646-bd37-383cc949da93").Return(&entities.User{
+				mock.EXPECT().GetByID(gomock.Any(), "91e3dcf7-34a6-4646-bd37-383cc949da93").Return(&entities.User{
 					ID:        "91e3dcf7-34a6-4646-bd37-383cc949da93",
 					FirstName: "John",
 					LastName:  "Dou",
@@ -242,35 +476,65 @@ func TestUserHandler_GetUserByID(t *testing.T) {
 		},
 		"invalid_uuid": {
 			id:                    "00000000--000-0000-0000-000000000000",
+			principal:             &entities.Principal{UserID: "91e3dcf7-34a6-4646-bd37-383cc949da93"},
 			usecaseBuilder:        func(ctrl *gomock.Controller) UserUsecase { return nil },
 			expectedStatusCode:    http.StatusBadRequest,
-			expectedResponsetBody: `{"message": "Bad request", "details": "invalid UUID format"}`,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:bad_request","title":"Bad Request","status":400,"code":"bad_request","detail":"invalid UUID format"}`,
+		},
+		"staff": {
+			id:        "91e3dcf7-34a6-4646-bd37-383cc949da93",
+			principal: &entities.Principal{UserID: "4fddf9a4-fbd1-4083-98aa-e4d0e584e7bb", Permissions: []string{entities.PermUsersList}},
+			usecaseBuilder: func(ctrl *gomock.Controller) UserUsecase {
+				mock := NewMockUserUsecase(ctrl)
+
+				mock.EXPECT().GetByID(gomock.Any(), "91e3dcf7-34a6-4646-bd37-383cc949da93").Return(&entities.User{
+					ID:        "91e3dcf7-34a6-4646-bd37-383cc949da93",
+					FirstName: "John",
+					Email:     "j.dou@test.com",
+					CreatedAt: "2022-01-01 00:00:10",
+				}, nil).Times(1)
+
+				return mock
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponsetBody: `{"id":"91e3dcf7-34a6-4646-bd37-383cc949da93", "first_name":"John", "last_name":"",
+									"email":"j.dou@test.com", "created_at":"2022-01-01 00:00:10"}`,
+		},
+		"foreign_account": {
+			id:                 "91e3dcf7-34a6-4646-bd37-383cc949da93",
+			principal:          &entities.Principal{UserID: "4fddf9a4-fbd1-4083-98aa-e4d0e584e7bb"},
+			usecaseBuilder:     func(ctrl *gomock.Controller) UserUsecase { return NewMockUserUsecase(ctrl) },
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:forbidden","title":"Forbidden","status":403,"code":"forbidden",` +
+				`"detail":"not allowed to act on this account"}`,
 		},
 		"user_not_found": {
-			id: "00000000-0000-0000-0000-000000000000",
+			id:        "00000000-0000-0000-0000-000000000000",
+			principal: &entities.Principal{UserID: "00000000-0000-0000-0000-000000000000"},
 			usecaseBuilder: func(ctrl *gomock.Controller) UserUsecase {
 				mock := NewMockUserUsecase(ctrl)
 
-				mock.EXPECT().GetByID("00000000-0000-0000-0000-000000000000").Return(nil,
-					globals.ErrNotFound).Times(1)
+				mock.EXPECT().GetByID(gomock.Any(), "00000000-0000-0000-0000-000000000000").Return(nil,
+					entities.ErrNotFound).Times(1)
 
 				return mock
 			},
 			expectedStatusCode:    http.StatusNotFound,
-			expectedResponsetBody: `{"message": "Not found", "details": "no user found in DB"}`,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:not_found","title":"Not Found","status":404,"code":"not_found","detail":"user not found"}`,
 		},
 		"internal_error": {
-			id: "10000000-0000-0000-0000-000000000000",
+			id:        "10000000-0000-0000-0000-000000000000",
+			principal: &entities.Principal{UserID: "10000000-0000-0000-0000-000000000000"},
 			usecaseBuilder: func(ctrl *gomock.Controller) UserUsecase {
 				mock := NewMockUserUsecase(ctrl)
 
-				mock.EXPECT().GetByID("10000000-0000-0000-0000-000000000000").Return(nil,
+				mock.EXPECT().GetByID(gomock.Any(), "10000000-0000-0000-0000-000000000000").Return(nil,
 					errors.New("internal error while processing test")).Times(1)
 
 				return mock
 			},
 			expectedStatusCode:    http.StatusInternalServerError,
-			expectedResponsetBody: `{"message": "Internal server error", "details": ""}`,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:internal_error","title":"Internal Server Error","status":500,"code":"internal_error"}`,
 		},
 	}
 
@@ -288,6 +552,7 @@ func TestUserHandler_GetUserByID(t *testing.T) {
 
 			request := httptest.NewRequest(http.MethodGet, "*", nil)
 			request = mux.SetURLVars(request, map[string]string{"id": tt.id})
+			request = request.WithContext(context.WithValue(request.Context(), principalCtxKey, tt.principal))
 
 			uh.GetUserByID(response, request)
 
@@ -303,18 +568,22 @@ func TestUserHandler_DeleteUser(t *testing.T) {
 		t.FailNow()
 	}
 
+	owner := &entities.Principal{UserID: "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc"}
+
 	tests := map[string]struct {
 		requestBody        string
+		principal          *entities.Principal
 		usecaseConstructor func(ctrl *gomock.Controller) UserUsecase
 		expStatusCode      int
 		expResponseBody    string
 	}{
 		"success": {
 			requestBody: `{"id": "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc"}`,
+			principal:   owner,
 			usecaseConstructor: func(ctrl *gomock.Controller) UserUsecase {
 				mock := NewMockUserUsecase(ctrl)
 
-				mock.EXPECT().Delete(entities.User{
+				mock.EXPECT().Delete(gomock.Any(), entities.User{
 					ID: "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc",
 				}).Return(nil).Times(1)
 
@@ -325,15 +594,16 @@ func TestUserHandler_DeleteUser(t *testing.T) {
 		},
 		"invalid_notexisted_id": {
 			requestBody: `{"id": "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc"}`,
+			principal:   owner,
 			usecaseConstructor: func(ctrl *gomock.Controller) UserUsecase {
 				mock := NewMockUserUsecase(ctrl)
-				mock.EXPECT().Delete(entities.User{
+				mock.EXPECT().Delete(gomock.Any(), entities.User{
 					ID: "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc",
-				}).Return(globals.ErrNotFound).Times(1)
+				}).Return(entities.ErrNotFound).Times(1)
 
 				return mock
 			},
-			expResponseBody: `{"message":"Not found","details":"no user found in DB"}`,
+			expResponseBody: `{"type":"urn:octagon:problem:not_found","title":"Not Found","status":404,"code":"not_found","detail":"user not found"}`,
 			expStatusCode:   http.StatusNotFound,
 		},
 		"invalid_bad_id_too_long": {
@@ -341,7 +611,7 @@ func TestUserHandler_DeleteUser(t *testing.T) {
 			usecaseConstructor: func(ctrl *gomock.Controller) UserUsecase {
 				return nil
 			},
-			expResponseBody: `{"message":"Bad request","details":"invalid uuid: invalid UUID length: 37"}`,
+			expResponseBody: `{"type":"urn:octagon:problem:bad_request","title":"Bad Request","status":400,"code":"bad_request","detail":"invalid uuid: invalid UUID length: 37"}`,
 			expStatusCode:   http.StatusBadRequest,
 		},
 		"invalid_bad_id_too_short": {
@@ -349,7 +619,7 @@ func TestUserHandler_DeleteUser(t *testing.T) {
 			usecaseConstructor: func(ctrl *gomock.Controller) UserUsecase {
 				return nil
 			},
-			expResponseBody: `{"message":"Bad request","details":"invalid uuid: invalid UUID length: 35"}`,
+			expResponseBody: `{"type":"urn:octagon:problem:bad_request","title":"Bad Request","status":400,"code":"bad_request","detail":"invalid uuid: invalid UUID length: 35"}`,
 			expStatusCode:   http.StatusBadRequest,
 		},
 		"invalid_bad_id_bad_symbpls": {
@@ -357,20 +627,57 @@ func TestUserHandler_DeleteUser(t *testing.T) {
 			usecaseConstructor: func(ctrl *gomock.Controller) UserUsecase {
 				return nil
 			},
-			expResponseBody: `{"message":"Bad request","details":"invalid uuid: invalid UUID format"}`,
+			expResponseBody: `{"type":"urn:octagon:problem:bad_request","title":"Bad Request","status":400,"code":"bad_request","detail":"invalid uuid: invalid UUID format"}`,
 			expStatusCode:   http.StatusBadRequest,
 		},
+		"admin_deletes_foreign_account": {
+			requestBody: `{"id": "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc"}`,
+			principal: &entities.Principal{
+				UserID:      "4fddf9a4-fbd1-4083-98aa-e4d0e584e7bb",
+				Roles:       []string{entities.RoleAdmin},
+				Permissions: []string{entities.PermUsersManage},
+			},
+			usecaseConstructor: func(ctrl *gomock.Controller) UserUsecase {
+				mock := NewMockUserUsecase(ctrl)
+
+				mock.EXPECT().Delete(gomock.Any(), entities.User{
+					ID: "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc",
+				}).Return(nil).Times(1)
+
+				return mock
+			},
+			expResponseBody: "",
+			expStatusCode:   http.StatusNoContent,
+		},
+		"forbidden_foreign_account": {
+			requestBody: `{"id": "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc"}`,
+			principal:   &entities.Principal{UserID: "4fddf9a4-fbd1-4083-98aa-e4d0e584e7bb"},
+			usecaseConstructor: func(ctrl *gomock.Controller) UserUsecase {
+				return nil
+			},
+			expResponseBody: `{"type":"urn:octagon:problem:forbidden","title":"Forbidden","status":403,"code":"forbidden","detail":"not allowed to act on this account"}`,
+			expStatusCode:   http.StatusForbidden,
+		},
+		"unauthenticated": {
+			requestBody: `{"id": "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc"}`,
+			usecaseConstructor: func(ctrl *gomock.Controller) UserUsecase {
+				return nil
+			},
+			expResponseBody: `{"type":"urn:octagon:problem:unauthorized","title":"Unauthorized","status":401,"code":"unauthorized","detail":"authentication required"}`,
+			expStatusCode:   http.StatusUnauthorized,
+		},
 		"invalid_internal_server_error": {
 			requestBody: `{"id": "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc"}`,
+			principal:   owner,
 			usecaseConstructor: func(ctrl *gomock.Controller) UserUsecase {
 				mock := NewMockUserUsecase(ctrl)
-				mock.EXPECT().Delete(entities.User{
+				mock.EXPECT().Delete(gomock.Any(), entities.User{
 					ID: "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc",
 				}).Return(errors.New("Internal error")).Times(1)
 
 				return mock
 			},
-			expResponseBody: `{"message":"Internal server error","details":""}`,
+			expResponseBody: `{"type":"urn:octagon:problem:internal_error","title":"Internal Server Error","status":500,"code":"internal_error"}`,
 			expStatusCode:   http.StatusInternalServerError,
 		},
 	}
@@ -384,7 +691,11 @@ func TestUserHandler_DeleteUser(t *testing.T) {
 				logger:  logger,
 			}
 			resp := httptest.NewRecorder()
-			uh.DeleteUser(resp, httptest.NewRequest(http.MethodDelete, "*", strings.NewReader(tt.requestBody)))
+			req := httptest.NewRequest(http.MethodDelete, "*", strings.NewReader(tt.requestBody))
+			if tt.principal != nil {
+				req = req.WithContext(context.WithValue(req.Context(), principalCtxKey, tt.principal))
+			}
+			uh.DeleteUser(resp, req)
 			ctrl.Finish()
 			require.Equal(t, tt.expStatusCode, resp.Code)
 			if len(tt.expResponseBody) > 0 {
@@ -393,3 +704,220 @@ func TestUserHandler_DeleteUser(t *testing.T) {
 		})
 	}
 }
+
+func TestUserHandler_UpdateUser(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	const id = "dca5947d-3dfc-49f1-bc09-dd53ce7e71cc"
+
+	firstName, email, empty := "Jane", "jane@example.com", ""
+
+	tests := map[string]struct {
+		requestBody        string
+		principal          *entities.Principal
+		usecaseConstructor func(ctrl *gomock.Controller) UserUsecase
+		expStatusCode      int
+		expResponseBody    string
+	}{
+		"success": {
+			requestBody: `{"first_name": "Jane", "last_name": null}`,
+			principal:   &entities.Principal{UserID: id},
+			usecaseConstructor: func(ctrl *gomock.Controller) UserUsecase {
+				mock := NewMockUserUsecase(ctrl)
+
+				mock.EXPECT().Update(gomock.Any(), id, entities.UserPatch{FirstName: &firstName, LastName: &empty}).Return(&entities.User{
+					ID:        id,
+					FirstName: "Jane",
+					Email:     "john@example.com",
+					CreatedAt: "2022-01-01 00:00:10",
+					UpdatedAt: "2022-02-01 00:00:10",
+				}, nil).Times(1)
+
+				return mock
+			},
+			expStatusCode: http.StatusOK,
+			expResponseBody: `{"id":"dca5947d-3dfc-49f1-bc09-dd53ce7e71cc", "first_name":"Jane", "last_name":"",
+								"email":"john@example.com", "created_at":"2022-01-01 00:00:10", "updated_at":"2022-02-01 00:00:10"}`,
+		},
+		"duplicate_email": {
+			requestBody: `{"email": "jane@example.com"}`,
+			principal:   &entities.Principal{UserID: id},
+			usecaseConstructor: func(ctrl *gomock.Controller) UserUsecase {
+				mock := NewMockUserUsecase(ctrl)
+				mock.EXPECT().Update(gomock.Any(), id, entities.UserPatch{Email: &email}).Return(nil, entities.ErrDuplicateEmail).Times(1)
+
+				return mock
+			},
+			expStatusCode:   http.StatusConflict,
+			expResponseBody: `{"type":"urn:octagon:problem:email_taken","title":"Conflict","status":409,"code":"email_taken","detail":"email is already taken"}`,
+		},
+		"invalid_email": {
+			requestBody: `{"email": "not-an-email"}`,
+			principal:   &entities.Principal{UserID: id},
+			usecaseConstructor: func(ctrl *gomock.Controller) UserUsecase {
+				return nil
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponseBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,
+								"code":"validation_failed","detail":"request has invalid fields",
+								"errors":[{"field":"email","code":"invalid","message":"must be a valid email address"}]}`,
+		},
+		"invalid_fields": {
+			requestBody: `{"first_name": "J", "last_name": "D0e", "email": ""}`,
+			principal:   &entities.Principal{UserID: id},
+			usecaseConstructor: func(ctrl *gomock.Controller) UserUsecase {
+				return nil
+			},
+			expStatusCode: http.StatusBadRequest,
+			expResponseBody: `{"type":"urn:octagon:problem:validation_failed","title":"Validation failed","status":400,
+								"code":"validation_failed","detail":"request has invalid fields",
+								"errors":[
+									{"field":"first_name","code":"invalid","message":"must be 2 to 256 letters, spaces, hyphens, apostrophes, dots or ampersands"},
+									{"field":"last_name","code":"invalid","message":"must be 2 to 256 letters, spaces, hyphens, apostrophes, dots or ampersands"},
+									{"field":"email","code":"required","message":"is required"}
+								]}`,
+		},
+		"remove_required_field": {
+			requestBody: `{"first_name": null}`,
+			principal:   &entities.Principal{UserID: id},
+			usecaseConstructor: func(ctrl *gomock.Controller) UserUsecase {
+				return nil
+			},
+			expStatusCode:   http.StatusBadRequest,
+			expResponseBody: `{"type":"urn:octagon:problem:bad_request","title":"Bad Request","status":400,"code":"bad_request","detail":"field \"first_name\" can not be removed"}`,
+		},
+		"unknown_field": {
+			requestBody: `{"password": "12345678Qwerty"}`,
+			principal:   &entities.Principal{UserID: id},
+			usecaseConstructor: func(ctrl *gomock.Controller) UserUsecase {
+				return nil
+			},
+			expStatusCode:   http.StatusBadRequest,
+			expResponseBody: `{"type":"urn:octagon:problem:bad_request","title":"Bad Request","status":400,"code":"bad_request","detail":"field \"password\" can not be patched"}`,
+		},
+		"forbidden_foreign_account": {
+			requestBody: `{"first_name": "Jane"}`,
+			principal:   &entities.Principal{UserID: "4fddf9a4-fbd1-4083-98aa-e4d0e584e7bb"},
+			usecaseConstructor: func(ctrl *gomock.Controller) UserUsecase {
+				return nil
+			},
+			expStatusCode:   http.StatusForbidden,
+			expResponseBody: `{"type":"urn:octagon:problem:forbidden","title":"Forbidden","status":403,"code":"forbidden","detail":"not allowed to act on this account"}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uh := UserHandler{
+				usecase: tt.usecaseConstructor(ctrl),
+				logger:  logger,
+			}
+
+			req := httptest.NewRequest(http.MethodPatch, "*", strings.NewReader(tt.requestBody))
+			req = mux.SetURLVars(req, map[string]string{"id": id})
+			req = req.WithContext(context.WithValue(req.Context(), principalCtxKey, tt.principal))
+
+			resp := httptest.NewRecorder()
+			uh.UpdateUser(resp, req)
+
+			require.Equal(t, tt.expStatusCode, resp.Code)
+			if len(tt.expResponseBody) > 0 {
+				require.JSONEq(t, tt.expResponseBody, resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestUserHandler_RestoreUser(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.Fatal("cannot initialize logger")
+	}
+
+	tests := map[string]struct {
+		id                    string
+		usecaseBuilder        func(ctrl *gomock.Controller) UserUsecase
+		expectedStatusCode    int
+		expectedResponsetBody string
+	}{
+		"success": {
+			id: "91e3dcf7-34a6-4646-bd37-383cc949da93",
+			usecaseBuilder: func(ctrl *gomock.Controller) UserUsecase {
+				mock := NewMockUserUsecase(ctrl)
+
+				mock.EXPECT().Restore(gomock.Any(), "91e3dcf7-34a6-4646-bd37-383cc949da93").Return(&entities.User{
+					ID:        "91e3dcf7-34a6-4646-bd37-383cc949da93",
+					FirstName: "John",
+					LastName:  "Dou",
+					Email:     "j.dou@test.com",
+					CreatedAt: "2022-01-01 00:00:10",
+				}, nil).Times(1)
+
+				return mock
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponsetBody: `{"id":"91e3dcf7-34a6-4646-bd37-383cc949da93", "first_name":"John", "last_name":"Dou",
+									"email":"j.dou@test.com", "created_at":"2022-01-01 00:00:10"}`,
+		},
+		"invalid_uuid": {
+			id:                    "00000000--000-0000-0000-000000000000",
+			usecaseBuilder:        func(ctrl *gomock.Controller) UserUsecase { return nil },
+			expectedStatusCode:    http.StatusBadRequest,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:bad_request","title":"Bad Request","status":400,"code":"bad_request","detail":"invalid UUID format"}`,
+		},
+		"grace_period_expired": {
+			id: "00000000-0000-0000-0000-000000000000",
+			usecaseBuilder: func(ctrl *gomock.Controller) UserUsecase {
+				mock := NewMockUserUsecase(ctrl)
+
+				mock.EXPECT().Restore(gomock.Any(), "00000000-0000-0000-0000-000000000000").Return(nil,
+					entities.ErrNotFound).Times(1)
+
+				return mock
+			},
+			expectedStatusCode:    http.StatusNotFound,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:not_found","title":"Not Found","status":404,"code":"not_found","detail":"no restorable user found"}`,
+		},
+		"email_taken": {
+			id: "10000000-0000-0000-0000-000000000000",
+			usecaseBuilder: func(ctrl *gomock.Controller) UserUsecase {
+				mock := NewMockUserUsecase(ctrl)
+
+				mock.EXPECT().Restore(gomock.Any(), "10000000-0000-0000-0000-000000000000").Return(nil,
+					entities.ErrDuplicateEmail).Times(1)
+
+				return mock
+			},
+			expectedStatusCode:    http.StatusConflict,
+			expectedResponsetBody: `{"type":"urn:octagon:problem:email_taken","title":"Conflict","status":409,"code":"email_taken","detail":"email is already taken"}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uh := UserHandler{
+				usecase: tt.usecaseBuilder(ctrl),
+				logger:  logger,
+			}
+
+			response := httptest.NewRecorder()
+
+			request := httptest.NewRequest(http.MethodPost, "*", nil)
+			request = mux.SetURLVars(request, map[string]string{"id": tt.id})
+
+			uh.RestoreUser(response, request)
+
+			require.Equal(t, tt.expectedStatusCode, response.Code)
+			require.JSONEq(t, tt.expectedResponsetBody, response.Body.String())
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/go-devs-ua/octagon/lgr"
+	"github.com/go-devs-ua/octagon/pkg/hash"
+	"github.com/go-devs-ua/octagon/pkg/token"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// AuthTTL holds on lifetimes of tokens issued by Auth.
+type AuthTTL struct {
+	Refresh       time.Duration
+	PasswordReset time.Duration
+}
+
+// Auth takes care of user sessions:
+// issuing, rotating and revoking tokens,
+// as well as of changing and resetting passwords
+// and verifying emails.
+// Unless RequireVerified is false, only verified users may log in.
+type Auth struct {
+	Users           User
+	Repo            AuthRepository
+	Tokens          TokenManager
+	Notifier        Notifier
+	TTL             AuthTTL
+	RequireVerified bool
+}
+
+// NewAuth will initialise new instance of Auth.
+func NewAuth(users User, repo AuthRepository, tokens TokenManager, notifier Notifier, ttl AuthTTL,
+	requireVerified bool,
+) Auth {
+	return Auth{
+		Users:           users,
+		Repo:            repo,
+		Tokens:          tokens,
+		Notifier:        notifier,
+		TTL:             ttl,
+		RequireVerified: requireVerified,
+	}
+}
+
+// Login checks user credentials and issues new pair of tokens.
+func (a Auth) Login(ctx context.Context, email, password string) (*entities.TokenPair, error) {
+	user, err := a.Users.Authenticate(ctx, email, password)
+	if err != nil {
+		return nil, fmt.Errorf("error while authenticating user: %w", err)
+	}
+
+	if a.RequireVerified && !user.IsVerified() {
+		return nil, entities.ErrEmailNotVerified
+	}
+
+	return a.issue(ctx, *user)
+}
+
+// Refresh exchanges refresh token for new pair of tokens.
+// Refresh token is rotated: the presented one is revoked.
+// Presenting token already revoked by rotation is treated as token theft
+// and ends all sessions of its owner.
+func (a Auth) Refresh(ctx context.Context, refreshToken string) (*entities.TokenPair, error) {
+	tokenHash := hash.SHA256(refreshToken)
+
+	old, err := a.Repo.RevokeRefreshToken(ctx, tokenHash)
+	if err != nil {
+		if !errors.Is(err, entities.ErrTokenNotFound) {
+			return nil, fmt.Errorf("error while revoking refresh token: %w", err)
+		}
+
+		if err := a.revokeOnReuse(ctx, tokenHash); err != nil {
+			return nil, err
+		}
+
+		return nil, entities.ErrInvalidToken.Wrap(err)
+	}
+
+	user, err := a.Users.GetByID(ctx, old.UserID)
+	if err != nil {
+		if errors.Is(err, entities.ErrNotFound) {
+			return nil, entities.ErrInvalidToken.Wrap(err)
+		}
+
+		return nil, err
+	}
+
+	return a.issue(ctx, *user)
+}
+
+// Logout deletes refresh token, so presenting it later is not taken for token theft.
+// Logging out with unknown or already revoked token is not an error.
+func (a Auth) Logout(ctx context.Context, refreshToken string) error {
+	if err := a.Repo.DeleteRefreshToken(ctx, hash.SHA256(refreshToken)); err != nil {
+		return fmt.Errorf("error while deleting refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// Verify parses access token and returns authenticated principal.
+func (a Auth) Verify(ctx context.Context, accessToken string) (*entities.Principal, error) {
+	claims, err := a.Tokens.Parse(accessToken)
+	if err != nil {
+		return nil, entities.ErrInvalidToken.Wrap(err)
+	}
+
+	return &entities.Principal{
+		UserID:      claims.Subject,
+		Roles:       claims.Roles,
+		Permissions: claims.Permissions,
+	}, nil
+}
+
+// issue embeds user roles and permissions granted by them into access token,
+// so they can be checked without hitting repository on every request.
+func (a Auth) issue(ctx context.Context, user entities.User) (*entities.TokenPair, error) {
+	permissions, err := a.Repo.FindPermissions(ctx, user.Roles)
+	if err != nil {
+		return nil, fmt.Errorf("error while fetching permissions: %w", err)
+	}
+
+	accessToken, expiresAt, err := a.Tokens.Issue(token.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: user.ID},
+		Roles:            user.Roles,
+		Permissions:      permissions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error while issuing access token: %w", err)
+	}
+
+	refreshToken, err := token.NewOpaque()
+	if err != nil {
+		return nil, fmt.Errorf("error while issuing refresh token: %w", err)
+	}
+
+	if err := a.Repo.AddRefreshToken(ctx, entities.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hash.SHA256(refreshToken),
+		ExpiresAt: time.Now().Add(a.TTL.Refresh),
+	}); err != nil {
+		return nil, fmt.Errorf("error while storing refresh token: %w", err)
+	}
+
+	return &entities.TokenPair{
+		AccessToken:  accessToken,
+		ExpiresAt:    expiresAt,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// ChangePassword replaces password of the user after checking the current one.
+// All sessions of the user are revoked.
+func (a Auth) ChangePassword(ctx context.Context, userID, current, password string) error {
+	user, err := a.Users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.Users.Authenticate(ctx, user.Email, current); err != nil {
+		if errors.Is(err, entities.ErrInvalidCredentials) {
+			return entities.ErrWrongPassword.Wrap(err)
+		}
+
+		return err
+	}
+
+	return a.setPassword(ctx, userID, password)
+}
+
+// RequestPasswordReset issues one-time password reset token
+// and sends it to the user. Unknown email is silently ignored,
+// so registered emails can not be enumerated. Tokens issued before
+// are revoked, so only the latest one can be used.
+func (a Auth) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := a.Users.Repo.FindUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, entities.ErrNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("error while searching user in database: %w", err)
+	}
+
+	if err := a.Repo.RevokeUserTokens(ctx, user.ID, entities.TokenPurposePasswordReset); err != nil {
+		return fmt.Errorf("error while revoking password reset tokens: %w", err)
+	}
+
+	resetToken, err := issueUserToken(ctx, a.Repo, user.ID, entities.TokenPurposePasswordReset, a.TTL.PasswordReset)
+	if err != nil {
+		return err
+	}
+
+	if err := a.Notifier.Notify(ctx, entities.Notification{
+		To:      user.Email,
+		Subject: "Password reset",
+		Body:    "Use this token to reset your password: " + resetToken,
+	}); err != nil {
+		return fmt.Errorf("error while sending password reset token: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword consumes password reset token and sets new password.
+// All sessions of the user are revoked.
+func (a Auth) ResetPassword(ctx context.Context, resetToken, password string) error {
+	stored, err := a.Repo.ConsumeUserToken(ctx, hash.SHA256(resetToken), entities.TokenPurposePasswordReset)
+	if err != nil {
+		if errors.Is(err, entities.ErrTokenNotFound) {
+			return entities.ErrInvalidToken.Wrap(err)
+		}
+
+		return fmt.Errorf("error while consuming password reset token: %w", err)
+	}
+
+	return a.setPassword(ctx, stored.UserID, password)
+}
+
+// RequestVerification sends new email verification token to the user.
+// Unknown or already verified email is silently ignored,
+// so registered emails can not be enumerated.
+func (a Auth) RequestVerification(ctx context.Context, email string) error {
+	user, err := a.Users.Repo.FindUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, entities.ErrNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("error while searching user in database: %w", err)
+	}
+
+	if user.IsVerified() {
+		return nil
+	}
+
+	if err := a.Users.Verifier.SendVerification(ctx, *user); err != nil {
+		return fmt.Errorf("error while sending email verification: %w", err)
+	}
+
+	return nil
+}
+
+// VerifyEmail consumes email verification token
+// and marks owner of the token verified.
+func (a Auth) VerifyEmail(ctx context.Context, verificationToken string) error {
+	stored, err := a.Repo.ConsumeUserToken(ctx, hash.SHA256(verificationToken), entities.TokenPurposeEmailVerification)
+	if err != nil {
+		if errors.Is(err, entities.ErrTokenNotFound) {
+			return entities.ErrInvalidToken.Wrap(err)
+		}
+
+		return fmt.Errorf("error while consuming email verification token: %w", err)
+	}
+
+	if err := a.Users.MarkVerified(ctx, stored.UserID); err != nil {
+		if errors.Is(err, entities.ErrNotFound) {
+			return entities.ErrInvalidToken.Wrap(err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (a Auth) setPassword(ctx context.Context, userID, password string) error {
+	if err := a.Users.SetPassword(ctx, userID, password); err != nil {
+		return err
+	}
+
+	// Sessions are deleted rather than revoked, so presenting their tokens
+	// is not taken for token theft ending sessions opened afterwards.
+	if err := a.Repo.DeleteUserRefreshTokens(ctx, userID); err != nil {
+		return fmt.Errorf("error while ending user sessions: %w", err)
+	}
+
+	return nil
+}
+
+func issueUserToken(ctx context.Context, repo UserTokenRepository, userID, purpose string, ttl time.Duration) (string, error) {
+	userToken, err := token.NewOpaque()
+	if err != nil {
+		return "", fmt.Errorf("error while issuing %s token: %w", purpose, err)
+	}
+
+	if err := repo.AddUserToken(ctx, entities.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hash.SHA256(userToken),
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return "", fmt.Errorf("error while storing %s token: %w", purpose, err)
+	}
+
+	return userToken, nil
+}
+
+func (a Auth) revokeOnReuse(ctx context.Context, tokenHash string) error {
+	stored, err := a.Repo.FindRefreshToken(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, entities.ErrTokenNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("error while searching refresh token: %w", err)
+	}
+
+	if stored.RevokedAt == nil {
+		return nil
+	}
+
+	lgr.FromContext(ctx).Warnw("Revoked refresh token reused, ending all user sessions.", "user", stored.UserID)
+
+	if err := a.Repo.DeleteUserRefreshTokens(ctx, stored.UserID); err != nil {
+		return fmt.Errorf("error while ending user sessions: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/go-devs-ua/octagon/app/repository/memory"
+	"github.com/go-devs-ua/octagon/pkg/hash"
+	"github.com/go-devs-ua/octagon/pkg/token"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuth_RequestPasswordReset(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRepo()
+	n := &notifier{}
+	users := NewUser(repo, hash.NewBcrypt(), NewVerification(repo, &notifier{}, time.Hour), noEvents{}, Retention{})
+	a := NewAuth(users, repo, nil, n, AuthTTL{PasswordReset: time.Hour}, false)
+
+	_, err := users.SignUp(ctx, entities.User{FirstName: "John", Email: "john@example.com", Password: "Password1"})
+	require.NoError(t, err)
+
+	resetToken := func() string {
+		require.NoError(t, a.RequestPasswordReset(ctx, "john@example.com"))
+
+		return strings.TrimPrefix(n.sent[len(n.sent)-1].Body, "Use this token to reset your password: ")
+	}
+
+	oldToken := resetToken()
+	newToken := resetToken()
+
+	err = a.ResetPassword(ctx, oldToken, "Password2")
+	require.ErrorIs(t, err, entities.ErrInvalidToken, "token issued before the latest one is revoked")
+
+	require.NoError(t, a.ResetPassword(ctx, newToken, "Password2"))
+
+	err = a.ResetPassword(ctx, newToken, "Password3")
+	require.ErrorIs(t, err, entities.ErrInvalidToken, "token can be used once")
+}
+
+func TestAuth_Sessions(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRepo()
+	users := NewUser(repo, hash.NewBcrypt(), NewVerification(repo, &notifier{}, time.Hour), noEvents{}, Retention{})
+	tokens := token.NewHS256([]byte("0123456789abcdef0123456789abcdef"), time.Minute)
+	a := NewAuth(users, repo, tokens, &notifier{}, AuthTTL{Refresh: time.Hour}, false)
+
+	id, err := users.SignUp(ctx, entities.User{FirstName: "John", Email: "john@example.com", Password: "Password1"})
+	require.NoError(t, err)
+
+	login := func(password string) *entities.TokenPair {
+		pair, err := a.Login(ctx, "john@example.com", password)
+		require.NoError(t, err)
+
+		return pair
+	}
+
+	t.Run("password_change_ends_sessions_for_good", func(t *testing.T) {
+		old := login("Password1")
+
+		require.NoError(t, a.ChangePassword(ctx, id, "Password1", "Password2"))
+
+		current := login("Password2")
+
+		_, err := a.Refresh(ctx, old.RefreshToken)
+		require.ErrorIs(t, err, entities.ErrInvalidToken)
+
+		_, err = a.Refresh(ctx, current.RefreshToken)
+		require.NoError(t, err, "presenting token of ended session does not end new sessions")
+	})
+
+	t.Run("logout_ends_session_for_good", func(t *testing.T) {
+		old := login("Password2")
+
+		require.NoError(t, a.Logout(ctx, old.RefreshToken))
+
+		current := login("Password2")
+
+		_, err := a.Refresh(ctx, old.RefreshToken)
+		require.ErrorIs(t, err, entities.ErrInvalidToken)
+
+		_, err = a.Refresh(ctx, current.RefreshToken)
+		require.NoError(t, err, "presenting token of ended session does not end new sessions")
+	})
+
+	t.Run("reused_rotated_token_ends_sessions", func(t *testing.T) {
+		stolen := login("Password2")
+
+		rotated, err := a.Refresh(ctx, stolen.RefreshToken)
+		require.NoError(t, err)
+
+		_, err = a.Refresh(ctx, stolen.RefreshToken)
+		require.ErrorIs(t, err, entities.ErrInvalidToken)
+
+		_, err = a.Refresh(ctx, rotated.RefreshToken)
+		require.ErrorIs(t, err, entities.ErrInvalidToken, "sessions are ended on token theft")
+
+		_, err = a.Refresh(ctx, login("Password2").RefreshToken)
+		require.NoError(t, err, "token theft is handled once")
+	})
+}
@@ -5,14 +5,79 @@
 package usecase
 
 import (
+	"context"
+	"time"
+
 	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/go-devs-ua/octagon/pkg/token"
 )
 
 // Repository interface can be implemented
 // in any kind of repositories like Postgres, MySQL etc.
 type Repository interface {
-	AddUser(entities.User) (string, error)
-	FindUser(string) (*entities.User, error)
-	GetAllUsers(entities.QueryParams) ([]entities.User, error)
-	DeleteUser(entities.User) error
+	AddUser(context.Context, entities.User) (string, error)
+	FindUser(context.Context, string) (*entities.User, error)
+	FindUserByEmail(context.Context, string) (*entities.User, error)
+	GetAllUsers(context.Context, entities.QueryParams) ([]entities.User, error)
+	CountUsers(context.Context, entities.Filter) (int64, error)
+	UpdateUser(context.Context, string, entities.UserPatch) (*entities.User, error)
+	UpdatePassword(context.Context, entities.User) error
+	MarkVerified(context.Context, string) error
+	DeleteUser(context.Context, entities.User) error
+	RestoreUser(ctx context.Context, id string, deletedAfter time.Time) (*entities.User, error)
+	PurgeUsers(ctx context.Context, deletedBefore time.Time) (int64, error)
+	AnonymizeUsers(ctx context.Context, deletedBefore time.Time) (int64, error)
+}
+
+// TokenRepository keeps refresh tokens issued to users.
+type TokenRepository interface {
+	AddRefreshToken(context.Context, entities.RefreshToken) error
+	FindRefreshToken(ctx context.Context, tokenHash string) (*entities.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, tokenHash string) (*entities.RefreshToken, error)
+	DeleteRefreshToken(ctx context.Context, tokenHash string) error
+	DeleteUserRefreshTokens(ctx context.Context, userID string) error
+}
+
+// RoleRepository keeps roles, their permissions and assignments to users.
+type RoleRepository interface {
+	ListRoles(ctx context.Context) ([]entities.Role, error)
+	FindPermissions(ctx context.Context, roles []string) ([]string, error)
+	AssignRole(ctx context.Context, userID, role string) error
+	RevokeRole(ctx context.Context, userID, role string) error
+}
+
+// UserTokenRepository keeps one-time tokens issued to users.
+type UserTokenRepository interface {
+	AddUserToken(context.Context, entities.UserToken) error
+	ConsumeUserToken(ctx context.Context, tokenHash, purpose string) (*entities.UserToken, error)
+	RevokeUserTokens(ctx context.Context, userID, purpose string) error
+}
+
+// AuthRepository combines repositories Auth depends on.
+type AuthRepository interface {
+	TokenRepository
+	RoleRepository
+	UserTokenRepository
+}
+
+// Notifier delivers notifications to users.
+type Notifier interface {
+	Notify(context.Context, entities.Notification) error
+}
+
+// EventRecorder records domain events, e.g. to count them.
+// Count tells how many times event occurred at once.
+type EventRecorder interface {
+	Record(event string, count int64)
+}
+
+// Verifier asks user to confirm ownership of the email.
+type Verifier interface {
+	SendVerification(context.Context, entities.User) error
+}
+
+// TokenManager issues and parses signed access tokens.
+type TokenManager interface {
+	Issue(token.Claims) (string, time.Time, error)
+	Parse(string) (*token.Claims, error)
 }
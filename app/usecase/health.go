@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+)
+
+// Check reports why dependency of the app is not ready
+// and returns nil when it is.
+type Check func(context.Context) error
+
+// shutdownCheck is name of the check failing once the app is draining.
+const shutdownCheck = "shutdown"
+
+// Health takes care of readiness of the app.
+// Dependencies register their checks, which are run concurrently,
+// each bound by Timeout. Once Drain is called the app is not ready anymore.
+type Health struct {
+	Timeout  time.Duration
+	mu       *sync.RWMutex
+	checks   map[string]Check
+	draining *atomic.Bool
+}
+
+// NewHealth will initialise new instance of Health without checks.
+func NewHealth(timeout time.Duration) Health {
+	return Health{
+		Timeout:  timeout,
+		mu:       new(sync.RWMutex),
+		checks:   make(map[string]Check),
+		draining: new(atomic.Bool),
+	}
+}
+
+// Register adds check under given name replacing check registered before under the same name.
+func (h Health) Register(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checks[name] = check
+}
+
+// Drain marks the app as shutting down, so it is not ready anymore.
+func (h Health) Drain() {
+	h.draining.Store(true)
+}
+
+// Ready runs all registered checks and reports their results.
+// It answers once Timeout passes even if some checks ignore the context,
+// reporting those as failed.
+func (h Health) Ready(ctx context.Context) entities.Health {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+
+	type result struct {
+		name string
+		err  error
+	}
+
+	report := entities.Health{Checks: make(map[string]error, len(h.checks)+1)}
+	results := make(chan result, len(h.checks))
+
+	if h.draining.Load() {
+		report.Checks[shutdownCheck] = entities.ErrShuttingDown
+	}
+
+	for name, check := range h.checks {
+		name, check := name, check
+
+		go func() {
+			results <- result{name: name, err: runCheck(ctx, check)}
+		}()
+	}
+
+	for range h.checks {
+		select {
+		case r := <-results:
+			report.Checks[r.name] = r.err
+		case <-ctx.Done():
+			for len(results) > 0 {
+				r := <-results
+				report.Checks[r.name] = r.err
+			}
+
+			for name := range h.checks {
+				if _, ok := report.Checks[name]; !ok {
+					report.Checks[name] = fmt.Errorf("check did not finish: %w", ctx.Err())
+				}
+			}
+
+			return report
+		}
+	}
+
+	return report
+}
+
+// runCheck turns panic of the check into its failure,
+// so a faulty check can not take the app down.
+func runCheck(ctx context.Context, check Check) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("check panicked: %v", r)
+		}
+	}()
+
+	return check(ctx)
+}
@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth_Ready(t *testing.T) {
+	stuck := make(chan struct{})
+	t.Cleanup(func() { close(stuck) })
+
+	h := NewHealth(50 * time.Millisecond)
+	h.Register("ok", func(context.Context) error { return nil })
+	h.Register("failing", func(context.Context) error { return errors.New("connection refused") })
+	h.Register("stuck", func(context.Context) error {
+		<-stuck
+
+		return nil
+	})
+	h.Register("panicking", func(context.Context) error { panic("boom") })
+
+	start := time.Now()
+	report := h.Ready(context.Background())
+
+	require.Less(t, time.Since(start), time.Second, "checks ignoring context do not hold readiness")
+	require.Len(t, report.Checks, 4)
+	require.NoError(t, report.Checks["ok"])
+	require.EqualError(t, report.Checks["failing"], "connection refused")
+	require.ErrorIs(t, report.Checks["stuck"], context.DeadlineExceeded)
+	require.Error(t, report.Checks["panicking"])
+
+	h.Drain()
+
+	require.ErrorIs(t, h.Ready(context.Background()).Checks[shutdownCheck], entities.ErrShuttingDown)
+}
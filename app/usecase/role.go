@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+)
+
+// Role takes care of managing role assignments.
+// Changes take effect on the next token refresh of affected user.
+type Role struct {
+	Users User
+	Repo  RoleRepository
+}
+
+// NewRole will initialise new instance of Role.
+func NewRole(users User, repo RoleRepository) Role {
+	return Role{Users: users, Repo: repo}
+}
+
+// List retrieves all roles along with their permissions.
+func (r Role) List(ctx context.Context) ([]entities.Role, error) {
+	roles, err := r.Repo.ListRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching roles from database: %w", err)
+	}
+
+	return roles, nil
+}
+
+// Assign grants role to existing user.
+func (r Role) Assign(ctx context.Context, userID, role string) error {
+	if _, err := r.Users.GetByID(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := r.Repo.AssignRole(ctx, userID, role); err != nil {
+		return fmt.Errorf("error while assigning role: %w", err)
+	}
+
+	return nil
+}
+
+// Revoke takes role away from existing user.
+func (r Role) Revoke(ctx context.Context, userID, role string) error {
+	if _, err := r.Users.GetByID(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := r.Repo.RevokeRole(ctx, userID, role); err != nil {
+		return fmt.Errorf("error while revoking role: %w", err)
+	}
+
+	return nil
+}
@@ -1,41 +1,71 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/go-devs-ua/octagon/app/entities"
-	"github.com/go-devs-ua/octagon/app/globals"
+	"github.com/go-devs-ua/octagon/lgr"
+	"github.com/go-devs-ua/octagon/pkg/hash"
 )
 
+// Retention describes for how long deleted users are kept.
+// Deleted user can be restored within RestoreGrace
+// and is purged after PurgeAfter: either removed
+// or, when Anonymize is set, stripped of personal data.
+type Retention struct {
+	RestoreGrace time.Duration
+	PurgeAfter   time.Duration
+	Anonymize    bool
+}
+
 type User struct {
-	Repo Repository
+	Repo      Repository
+	Hasher    hash.PasswordHasher
+	Verifier  Verifier
+	Events    EventRecorder
+	Retention Retention
 }
 
 // NewUser is a famous  trick with accepting
 // interfaces and returning struct.
-func NewUser(repo Repository) User {
-	return User{Repo: repo}
+func NewUser(repo Repository, hasher hash.PasswordHasher, verifier Verifier, events EventRecorder, retention Retention) User {
+	return User{Repo: repo, Hasher: hasher, Verifier: verifier, Events: events, Retention: retention}
 }
 
 // SignUp represents business logic
 // and will take care of creating user.
-func (u User) SignUp(user entities.User) (string, error) {
-	id, err := u.Repo.AddUser(user)
+// New user is unverified until the email is confirmed.
+// Verification is sent on best effort basis: user is created
+// even if it fails, as verification can be requested again.
+func (u User) SignUp(ctx context.Context, user entities.User) (string, error) {
+	hashed, err := u.hashPassword(user.Password)
 	if err != nil {
-		if errors.Is(err, globals.ErrDuplicateEmail) {
-			return "", globals.ErrDuplicateEmail
-		}
+		return "", err
+	}
+
+	user.Password = hashed
 
+	id, err := u.Repo.AddUser(ctx, user)
+	if err != nil {
 		return "", fmt.Errorf("error while adding user to database: %w", err)
 	}
 
+	user.ID = id
+	u.Events.Record(entities.EventUserSignedUp, 1)
+
+	if err := u.Verifier.SendVerification(ctx, user); err != nil {
+		lgr.FromContext(ctx).Errorw("Error while sending email verification.", "user", id, "error", err.Error())
+	}
+
 	return id, nil
 }
 
 // GetByID takes care of finding user by ID.
-func (u User) GetByID(id string) (*entities.User, error) {
-	user, err := u.Repo.FindUser(id)
+func (u User) GetByID(ctx context.Context, id string) (*entities.User, error) {
+	user, err := u.Repo.FindUser(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("error while searching user in database: %w", err)
 	}
@@ -44,8 +74,8 @@ func (u User) GetByID(id string) (*entities.User, error) {
 }
 
 // GetAll retrieves all suitable users from repository.
-func (u User) GetAll(params entities.QueryParams) ([]entities.User, error) {
-	users, err := u.Repo.GetAllUsers(params)
+func (u User) GetAll(ctx context.Context, params entities.QueryParams) ([]entities.User, error) {
+	users, err := u.Repo.GetAllUsers(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching users from database: %w", err)
 	}
@@ -53,13 +83,165 @@ func (u User) GetAll(params entities.QueryParams) ([]entities.User, error) {
 	return users, nil
 }
 
+// Count counts users matching the filter.
+func (u User) Count(ctx context.Context, filter entities.Filter) (int64, error) {
+	n, err := u.Repo.CountUsers(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("error counting users in database: %w", err)
+	}
+
+	return n, nil
+}
+
+// Update applies patch to the user profile.
+// Empty patch changes nothing and returns user as is.
+// Changed email has to be verified again. As in SignUp, verification
+// is sent on best effort basis, since the change is already stored.
+func (u User) Update(ctx context.Context, id string, patch entities.UserPatch) (*entities.User, error) {
+	if patch.IsEmpty() {
+		return u.GetByID(ctx, id)
+	}
+
+	user, err := u.Repo.UpdateUser(ctx, id, patch)
+	if err != nil {
+		return nil, fmt.Errorf("error while updating user in database: %w", err)
+	}
+
+	if patch.Email != nil && !user.IsVerified() {
+		if err := u.Verifier.SendVerification(ctx, *user); err != nil {
+			lgr.FromContext(ctx).Errorw("Error while sending email verification.", "user", id, "error", err.Error())
+		}
+	}
+
+	return user, nil
+}
+
+// SetPassword hashes and stores new password of the user.
+func (u User) SetPassword(ctx context.Context, id, password string) error {
+	hashed, err := u.hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := u.Repo.UpdatePassword(ctx, entities.User{ID: id, Password: hashed}); err != nil {
+		return fmt.Errorf("error while updating password: %w", err)
+	}
+
+	return nil
+}
+
+// MarkVerified records that user confirmed ownership of the email.
+func (u User) MarkVerified(ctx context.Context, id string) error {
+	if err := u.Repo.MarkVerified(ctx, id); err != nil {
+		return fmt.Errorf("error while verifying user: %w", err)
+	}
+
+	return nil
+}
+
 // Delete represents business logic
 // and will take care of deleting user.
-func (u User) Delete(user entities.User) error {
-	err := u.Repo.DeleteUser(user)
+func (u User) Delete(ctx context.Context, user entities.User) error {
+	err := u.Repo.DeleteUser(ctx, user)
 	if err != nil {
 		return fmt.Errorf("error while deleting user from database: %w", err)
 	}
 
+	u.Events.Record(entities.EventUserDeleted, 1)
+
 	return nil
 }
+
+// Restore undoes deletion of the user within restore grace period.
+func (u User) Restore(ctx context.Context, id string) (*entities.User, error) {
+	user, err := u.Repo.RestoreUser(ctx, id, time.Now().Add(-u.Retention.RestoreGrace))
+	if err != nil {
+		return nil, fmt.Errorf("error while restoring user in database: %w", err)
+	}
+
+	u.Events.Record(entities.EventUserRestored, 1)
+
+	return user, nil
+}
+
+// Purge removes or anonymises users deleted longer than retention period ago
+// and returns number of purged users.
+func (u User) Purge(ctx context.Context) (int64, error) {
+	deletedBefore := time.Now().Add(-u.Retention.PurgeAfter)
+
+	if u.Retention.Anonymize {
+		n, err := u.Repo.AnonymizeUsers(ctx, deletedBefore)
+		if err != nil {
+			return 0, fmt.Errorf("error while anonymising users in database: %w", err)
+		}
+
+		u.Events.Record(entities.EventUserPurged, n)
+
+		return n, nil
+	}
+
+	n, err := u.Repo.PurgeUsers(ctx, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("error while purging users from database: %w", err)
+	}
+
+	u.Events.Record(entities.EventUserPurged, n)
+
+	return n, nil
+}
+
+// Authenticate checks user credentials.
+// Password hash produced by outdated algorithm or parameters
+// is transparently upgraded to the current one.
+func (u User) Authenticate(ctx context.Context, email, password string) (*entities.User, error) {
+	user, err := u.Repo.FindUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, entities.ErrNotFound) {
+			return nil, entities.ErrInvalidCredentials.Wrap(err)
+		}
+
+		return nil, fmt.Errorf("error while searching user in database: %w", err)
+	}
+
+	match, rehash, err := hash.Verify(u.Hasher, password, user.Password)
+	if err != nil {
+		return nil, fmt.Errorf("error while verifying password: %w", err)
+	}
+
+	if !match {
+		return nil, entities.ErrInvalidCredentials
+	}
+
+	if rehash {
+		hashed, err := u.Hasher.Hash(password)
+
+		switch {
+		case errors.Is(err, hash.ErrPasswordTooLong):
+			// Password set before hasher was switched keeps its current hash.
+		case err != nil:
+			return nil, fmt.Errorf("error while rehashing password: %w", err)
+		default:
+			if err := u.Repo.UpdatePassword(ctx, entities.User{ID: user.ID, Password: hashed}); err != nil {
+				return nil, fmt.Errorf("error while updating password hash: %w", err)
+			}
+		}
+	}
+
+	user.Password = ""
+
+	return user, nil
+}
+
+// hashPassword hashes password reporting one hasher can not take in full as invalid.
+func (u User) hashPassword(password string) (string, error) {
+	hashed, err := u.Hasher.Hash(password)
+	if errors.Is(err, hash.ErrPasswordTooLong) {
+		return "", entities.ErrPasswordTooLong.Wrap(err)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("error while hashing password: %w", err)
+	}
+
+	return hashed, nil
+}
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+	"github.com/go-devs-ua/octagon/app/repository/memory"
+	"github.com/go-devs-ua/octagon/pkg/hash"
+	"github.com/stretchr/testify/require"
+)
+
+// notifier keeps sent notifications or fails with err when it is set.
+type notifier struct {
+	sent []entities.Notification
+	err  error
+}
+
+func (n *notifier) Notify(_ context.Context, notification entities.Notification) error {
+	if n.err != nil {
+		return n.err
+	}
+
+	n.sent = append(n.sent, notification)
+
+	return nil
+}
+
+type noEvents struct{}
+
+func (noEvents) Record(string, int64) {}
+
+func TestUser_SignUp(t *testing.T) {
+	for name, tt := range map[string]struct {
+		notifyErr error
+		expSent   int
+	}{
+		"sends_verification": {expSent: 1},
+		"notifier_fails":     {notifyErr: errors.New("smtp is down")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := memory.NewRepo()
+			n := &notifier{err: tt.notifyErr}
+			u := NewUser(repo, hash.NewBcrypt(), NewVerification(repo, n, time.Hour), noEvents{}, Retention{})
+
+			id, err := u.SignUp(ctx, entities.User{FirstName: "John", Email: "john@example.com", Password: "Password1"})
+			require.NoError(t, err, "user is created even if verification is not sent")
+			require.Len(t, n.sent, tt.expSent)
+
+			user, err := repo.FindUser(ctx, id)
+			require.NoError(t, err)
+			require.Equal(t, "john@example.com", user.Email)
+
+			_, err = u.SignUp(ctx, entities.User{FirstName: "John", Email: "john@example.com", Password: "Password1"})
+			require.ErrorIs(t, err, entities.ErrDuplicateEmail)
+		})
+	}
+}
+
+func TestUser_Update(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRepo()
+	n := &notifier{}
+	u := NewUser(repo, hash.NewBcrypt(), NewVerification(repo, n, time.Hour), noEvents{}, Retention{})
+
+	id, err := u.SignUp(ctx, entities.User{FirstName: "John", Email: "john@example.com", Password: "Password1"})
+	require.NoError(t, err)
+
+	n.err = errors.New("smtp is down")
+	email := "jack@example.com"
+
+	user, err := u.Update(ctx, id, entities.UserPatch{Email: &email})
+	require.NoError(t, err, "email is changed even if verification is not sent")
+	require.Equal(t, email, user.Email)
+
+	stored, err := repo.FindUser(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, email, stored.Email)
+}
+
+func TestUser_PasswordTooLong(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRepo()
+	long := strings.Repeat("a", hash.BcryptMaxPasswordBytes) + "1"
+
+	u := NewUser(repo, hash.NewBcrypt(), NewVerification(repo, &notifier{}, time.Hour), noEvents{}, Retention{})
+
+	_, err := u.SignUp(ctx, entities.User{FirstName: "John", Email: "john@example.com", Password: long})
+	require.ErrorIs(t, err, entities.ErrPasswordTooLong)
+
+	// Password set while another hasher was used still works, it is just not rehashed.
+	argon := NewUser(repo, hash.NewArgon2ID(), NewVerification(repo, &notifier{}, time.Hour), noEvents{}, Retention{})
+
+	_, err = argon.SignUp(ctx, entities.User{FirstName: "John", Email: "john@example.com", Password: long})
+	require.NoError(t, err)
+
+	_, err = u.Authenticate(ctx, "john@example.com", long)
+	require.NoError(t, err)
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-devs-ua/octagon/app/entities"
+)
+
+// Verification takes care of asking users
+// to confirm ownership of their emails.
+type Verification struct {
+	Repo     UserTokenRepository
+	Notifier Notifier
+	TTL      time.Duration
+}
+
+// NewVerification will initialise new instance of Verification.
+func NewVerification(repo UserTokenRepository, notifier Notifier, ttl time.Duration) Verification {
+	return Verification{
+		Repo:     repo,
+		Notifier: notifier,
+		TTL:      ttl,
+	}
+}
+
+// SendVerification issues email verification token and sends it to the user.
+// Tokens issued before are revoked, so only the latest one can be used.
+func (v Verification) SendVerification(ctx context.Context, user entities.User) error {
+	if err := v.Repo.RevokeUserTokens(ctx, user.ID, entities.TokenPurposeEmailVerification); err != nil {
+		return fmt.Errorf("error while revoking email verification tokens: %w", err)
+	}
+
+	verificationToken, err := issueUserToken(ctx, v.Repo, user.ID, entities.TokenPurposeEmailVerification, v.TTL)
+	if err != nil {
+		return err
+	}
+
+	if err := v.Notifier.Notify(ctx, entities.Notification{
+		To:      user.Email,
+		Subject: "Email verification",
+		Body:    "Use this token to verify your email: " + verificationToken,
+	}); err != nil {
+		return fmt.Errorf("error while sending email verification token: %w", err)
+	}
+
+	return nil
+}
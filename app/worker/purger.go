@@ -0,0 +1,60 @@
+// Package worker lives in app dir and holds on background jobs
+// which are run alongside transport layer.
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-devs-ua/octagon/lgr"
+)
+
+// PurgeUsecase represents use-case purging deleted users.
+type PurgeUsecase interface {
+	Purge(context.Context) (int64, error)
+}
+
+// Purger periodically purges users deleted longer than retention period ago.
+type Purger struct {
+	usecase  PurgeUsecase
+	interval time.Duration
+	logger   *lgr.Logger
+}
+
+// NewPurger will initialise new instance of Purger.
+func NewPurger(usecase PurgeUsecase, interval time.Duration, logger *lgr.Logger) Purger {
+	return Purger{
+		usecase:  usecase,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run purges users right away and then every interval until ctx is done.
+func (p Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p Purger) purge(ctx context.Context) {
+	n, err := p.usecase.Purge(ctx)
+	if err != nil {
+		p.logger.Errorw("Failed purging deleted users.", "error", err.Error())
+
+		return
+	}
+
+	if n > 0 {
+		p.logger.Infow("Deleted users purged", "count", n)
+	}
+}
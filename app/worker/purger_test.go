@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-devs-ua/octagon/lgr"
+	"github.com/stretchr/testify/require"
+)
+
+type purgeFunc func(context.Context) (int64, error)
+
+func (f purgeFunc) Purge(ctx context.Context) (int64, error) { return f(ctx) }
+
+func TestPurger_Run(t *testing.T) {
+	logger, err := lgr.New(lgr.InfoLevel)
+	if err != nil {
+		t.FailNow()
+	}
+
+	calls := make(chan struct{}, 3)
+	usecase := purgeFunc(func(context.Context) (int64, error) {
+		calls <- struct{}{}
+
+		return 0, errors.New("temporary failure")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		NewPurger(usecase, time.Millisecond, logger).Run(ctx)
+		close(done)
+	}()
+
+	// Failed purge does not stop purger.
+	for i := 0; i < cap(calls); i++ {
+		<-calls
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.FailNow(t, "purger did not stop after context was cancelled")
+	}
+}
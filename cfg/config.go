@@ -5,11 +5,15 @@ package cfg
 
 import (
 	"bufio"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log"
+	"net/netip"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Allowed logger levels & config key.
@@ -18,10 +22,57 @@ const (
 	InfoLogLvl      = "INFO"
 	ErrorLogLvl     = "ERROR"
 	LogLvlConfigKey = "LOG_LEVEL"
+	HasherConfigKey = "PASSWORD_HASHER"
 	lenOfLines      = 2
 	envFileName     = ".env"
 )
 
+// Allowed token signing methods & defaults.
+const (
+	HS256SigningMethod      = "HS256"
+	EdDSASigningMethod      = "EdDSA"
+	minSecretLength         = 32
+	defaultAccessTokenTTL   = 15 * time.Minute
+	defaultRefreshTokenTTL  = 30 * 24 * time.Hour
+	defaultPasswordResetTTL = time.Hour
+	defaultVerificationTTL  = 24 * time.Hour
+	defaultTokenIssuer      = "octagon"
+)
+
+// Server defaults.
+const (
+	defaultShutdownTimeout  = 15 * time.Second
+	defaultDrainDelay       = 5 * time.Second
+	defaultReadinessTimeout = 2 * time.Second
+	defaultMaxBodyBytes     = 1 << 20
+	defaultCORSMaxAge       = 10 * time.Minute
+	defaultTLSReload        = 30 * time.Second
+	AnyOrigin               = "*"
+)
+
+// Allowed purge modes & retention defaults.
+const (
+	DeletePurgeMode      = "delete"
+	AnonymizePurgeMode   = "anonymize"
+	defaultRestoreGrace  = 30 * 24 * time.Hour
+	defaultPurgeAfter    = 90 * 24 * time.Hour
+	defaultPurgeInterval = time.Hour
+)
+
+// Allowed database drivers & defaults.
+const (
+	PostgresDriver      = "postgres"
+	SQLiteDriver        = "sqlite"
+	MemoryDriver        = "memory"
+	defaultQueryTimeout = 5 * time.Second
+)
+
+// Allowed notifiers.
+const (
+	LogNotifier  = "log"
+	FileNotifier = "file"
+)
+
 // Load configs from a env file & sets them in environment variables.
 func loadEnvVar() error {
 	f, err := os.Open(envFileName)
@@ -59,25 +110,122 @@ func loadEnvVar() error {
 	return nil
 }
 
+// DB configuration description.
+// Connection parameters are used by postgres driver
+// and Path to database file by sqlite driver.
+// Zero QueryTimeout leaves queries bound by request only.
 type DB struct {
-	Host     string
-	Port     string
-	Username string
-	Password string
-	DBName   string
+	Driver       string
+	Path         string
+	QueryTimeout time.Duration
+	Host         string
+	Port         string
+	Username     string
+	Password     string
+	DBName       string
 }
 
 // Server configuration description.
+// On shutdown server fails readiness checks, keeps serving for DrainDelay,
+// so load balancers notice it, and then drains in-flight requests for at most
+// ShutdownTimeout. ReadinessTimeout limits how long readiness checks are run.
+// Request bodies larger than MaxBodyBytes are rejected.
 type Server struct {
-	Host string
-	Port string
+	Host             string
+	Port             string
+	ShutdownTimeout  time.Duration
+	DrainDelay       time.Duration
+	ReadinessTimeout time.Duration
+	MaxBodyBytes     int64
+	CORS             CORS
+	TLS              TLS
+}
+
+// TLS configuration description.
+// Server is served over TLS either with certificate loaded from CertFile and KeyFile,
+// which are checked for changes every ReloadInterval, or with SelfSigned one
+// generated on start for development. Zero ReloadInterval disables reloading.
+// HTTP2 is negotiated with clients only over TLS.
+type TLS struct {
+	CertFile       string
+	KeyFile        string
+	MinVersion     uint16
+	SelfSigned     bool
+	HTTP2          bool
+	ReloadInterval time.Duration
+}
+
+// Enabled reports whether server is served over TLS.
+func (t TLS) Enabled() bool {
+	return t.SelfSigned || t.CertFile != ""
+}
+
+// CORS configuration description.
+// No AllowedOrigins disables cross-origin requests,
+// while AnyOrigin allows all of them.
+// MaxAge tells for how long browsers cache preflight responses.
+type CORS struct {
+	AllowedOrigins []string
+	MaxAge         time.Duration
+}
+
+// Auth configuration description.
+type Auth struct {
+	SigningMethod    string
+	Secret           string
+	PrivateKeyFile   string
+	Issuer           string
+	Audience         string
+	AccessTokenTTL   time.Duration
+	RefreshTokenTTL  time.Duration
+	PasswordResetTTL time.Duration
+	VerificationTTL  time.Duration
+	// AllowUnverified lets users log in before they verify email.
+	AllowUnverified bool
+}
+
+// Notifier configuration description.
+type Notifier struct {
+	Kind string
+	File string
+}
+
+// Retention configuration description.
+// Zero PurgeInterval disables purging.
+type Retention struct {
+	RestoreGrace  time.Duration
+	PurgeAfter    time.Duration
+	PurgeInterval time.Duration
+	PurgeMode     string
+}
+
+// Rate allows Requests per Period.
+// Zero Rate does not limit requests.
+type Rate struct {
+	Requests int
+	Period   time.Duration
+}
+
+// RateLimit configuration description.
+// Default rate applies to routes without own rate in Routes,
+// which are keyed by method and path template, e.g. "POST /users".
+// X-Forwarded-For header is trusted only when sent by TrustedProxies.
+type RateLimit struct {
+	Default        Rate
+	Routes         map[string]Rate
+	TrustedProxies []netip.Prefix
 }
 
 // Options will keep all needful configs.
 type Options struct {
-	LogLevel string
-	Server   Server
-	DB       DB
+	LogLevel       string
+	PasswordHasher string
+	Server         Server
+	DB             DB
+	Auth           Auth
+	Notifier       Notifier
+	Retention      Retention
+	RateLimit      RateLimit
 }
 
 // GetConfig will create instance of Options
@@ -87,19 +235,135 @@ func GetConfig() (Options, error) {
 		return Options{}, err
 	}
 
+	accessTTL, err := durationFromEnv("ACCESS_TOKEN_TTL", defaultAccessTokenTTL)
+	if err != nil {
+		return Options{}, err
+	}
+
+	refreshTTL, err := durationFromEnv("REFRESH_TOKEN_TTL", defaultRefreshTokenTTL)
+	if err != nil {
+		return Options{}, err
+	}
+
+	resetTTL, err := durationFromEnv("PASSWORD_RESET_TTL", defaultPasswordResetTTL)
+	if err != nil {
+		return Options{}, err
+	}
+
+	verificationTTL, err := durationFromEnv("EMAIL_VERIFICATION_TTL", defaultVerificationTTL)
+	if err != nil {
+		return Options{}, err
+	}
+
+	allowUnverified, err := boolFromEnv("ALLOW_UNVERIFIED_LOGIN", false)
+	if err != nil {
+		return Options{}, err
+	}
+
+	restoreGrace, err := durationFromEnv("USER_RESTORE_GRACE", defaultRestoreGrace)
+	if err != nil {
+		return Options{}, err
+	}
+
+	purgeAfter, err := durationFromEnv("USER_PURGE_AFTER", defaultPurgeAfter)
+	if err != nil {
+		return Options{}, err
+	}
+
+	purgeInterval, err := durationFromEnv("USER_PURGE_INTERVAL", defaultPurgeInterval)
+	if err != nil {
+		return Options{}, err
+	}
+
+	queryTimeout, err := durationFromEnv("DB_QUERY_TIMEOUT", defaultQueryTimeout)
+	if err != nil {
+		return Options{}, err
+	}
+
+	shutdownTimeout, err := durationFromEnv("SERV_SHUTDOWN_TIMEOUT", defaultShutdownTimeout)
+	if err != nil {
+		return Options{}, err
+	}
+
+	drainDelay, err := durationFromEnv("SERV_DRAIN_DELAY", defaultDrainDelay)
+	if err != nil {
+		return Options{}, err
+	}
+
+	readinessTimeout, err := durationFromEnv("SERV_READINESS_TIMEOUT", defaultReadinessTimeout)
+	if err != nil {
+		return Options{}, err
+	}
+
+	maxBodyBytes, err := int64FromEnv("SERV_MAX_BODY_BYTES", defaultMaxBodyBytes)
+	if err != nil {
+		return Options{}, err
+	}
+
+	corsMaxAge, err := durationFromEnv("CORS_MAX_AGE", defaultCORSMaxAge)
+	if err != nil {
+		return Options{}, err
+	}
+
+	rateLimit, err := rateLimitFromEnv()
+	if err != nil {
+		return Options{}, err
+	}
+
+	tlsOpt, err := tlsFromEnv()
+	if err != nil {
+		return Options{}, err
+	}
+
 	opt := Options{
-		LogLevel: os.Getenv(LogLvlConfigKey),
+		LogLevel:       os.Getenv(LogLvlConfigKey),
+		PasswordHasher: os.Getenv(HasherConfigKey),
 		Server: Server{
-			Host: os.Getenv("SERV_HOST"),
-			Port: os.Getenv("SERV_PORT"),
+			Host:             os.Getenv("SERV_HOST"),
+			Port:             os.Getenv("SERV_PORT"),
+			ShutdownTimeout:  shutdownTimeout,
+			DrainDelay:       drainDelay,
+			ReadinessTimeout: readinessTimeout,
+			MaxBodyBytes:     maxBodyBytes,
+			CORS: CORS{
+				AllowedOrigins: listFromEnv("CORS_ALLOWED_ORIGINS"),
+				MaxAge:         corsMaxAge,
+			},
+			TLS: tlsOpt,
 		},
 		DB: DB{
-			Host:     os.Getenv("DB_HOST"),
-			Port:     os.Getenv("DB_PORT"),
-			Username: os.Getenv("DB_USER"),
-			Password: os.Getenv("DB_PASSWORD"),
-			DBName:   os.Getenv("DB_NAME"),
+			Driver:       os.Getenv("DB_DRIVER"),
+			Path:         os.Getenv("DB_PATH"),
+			QueryTimeout: queryTimeout,
+			Host:         os.Getenv("DB_HOST"),
+			Port:         os.Getenv("DB_PORT"),
+			Username:     os.Getenv("DB_USER"),
+			Password:     os.Getenv("DB_PASSWORD"),
+			DBName:       os.Getenv("DB_NAME"),
+		},
+		Auth: Auth{
+			SigningMethod:    os.Getenv("JWT_SIGNING_METHOD"),
+			Secret:           os.Getenv("JWT_SECRET"),
+			PrivateKeyFile:   os.Getenv("JWT_PRIVATE_KEY_FILE"),
+			Issuer:           stringFromEnv("JWT_ISSUER", defaultTokenIssuer),
+			Audience:         stringFromEnv("JWT_AUDIENCE", defaultTokenIssuer),
+			AccessTokenTTL:   accessTTL,
+			RefreshTokenTTL:  refreshTTL,
+			PasswordResetTTL: resetTTL,
+			VerificationTTL:  verificationTTL,
+			AllowUnverified:  allowUnverified,
 		},
+		Notifier: Notifier{
+			Kind: os.Getenv("NOTIFIER"),
+			File: os.Getenv("NOTIFIER_FILE"),
+		},
+		Retention: Retention{
+			RestoreGrace:  restoreGrace,
+			PurgeAfter:    purgeAfter,
+			PurgeInterval: purgeInterval,
+			PurgeMode:     os.Getenv("USER_PURGE_MODE"),
+		},
+		RateLimit: rateLimit,
 	}
 
 	if err := opt.validate(); err != nil {
@@ -116,5 +380,266 @@ func (opt Options) validate() error {
 		return fmt.Errorf("\"%v\" is not allowed logger level", opt.LogLevel)
 	}
 
+	switch opt.Auth.SigningMethod {
+	case HS256SigningMethod:
+		if len(opt.Auth.Secret) < minSecretLength {
+			return fmt.Errorf("JWT secret has to be at least %d characters long", minSecretLength)
+		}
+	case EdDSASigningMethod:
+		if opt.Auth.PrivateKeyFile == "" {
+			return errors.New("JWT private key file is required for EdDSA signing method")
+		}
+	default:
+		return fmt.Errorf("\"%v\" is not allowed signing method", opt.Auth.SigningMethod)
+	}
+
+	if opt.Server.DrainDelay < 0 {
+		return errors.New("drain delay can not be negative")
+	}
+
+	if opt.Server.MaxBodyBytes <= 0 {
+		return errors.New("max body size has to be positive")
+	}
+
+	for _, origin := range opt.Server.CORS.AllowedOrigins {
+		if origin != AnyOrigin && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			return fmt.Errorf("\"%v\" is not allowed CORS origin", origin)
+		}
+	}
+
+	if (opt.Server.TLS.CertFile == "") != (opt.Server.TLS.KeyFile == "") {
+		return errors.New("TLS certificate and key files have to be set together")
+	}
+
+	if opt.Server.TLS.SelfSigned && opt.Server.TLS.CertFile != "" {
+		return errors.New("self-signed TLS certificate can not be used along with certificate files")
+	}
+
+	if opt.Server.TLS.ReloadInterval < 0 {
+		return errors.New("TLS reload interval can not be negative")
+	}
+
+	switch opt.DB.Driver {
+	case PostgresDriver, MemoryDriver, "":
+	case SQLiteDriver:
+		if opt.DB.Path == "" {
+			return errors.New("database file path is required for sqlite driver")
+		}
+	default:
+		return fmt.Errorf("\"%v\" is not allowed database driver", opt.DB.Driver)
+	}
+
+	switch opt.Notifier.Kind {
+	case LogNotifier, "":
+	case FileNotifier:
+		if opt.Notifier.File == "" {
+			return errors.New("notifications file is required for file notifier")
+		}
+	default:
+		return fmt.Errorf("\"%v\" is not allowed notifier", opt.Notifier.Kind)
+	}
+
+	switch opt.Retention.PurgeMode {
+	case DeletePurgeMode, AnonymizePurgeMode, "":
+	default:
+		return fmt.Errorf("\"%v\" is not allowed purge mode", opt.Retention.PurgeMode)
+	}
+
+	if opt.Retention.PurgeAfter < opt.Retention.RestoreGrace {
+		return errors.New("users can not be purged before restore grace period ends")
+	}
+
 	return nil
 }
+
+// stringFromEnv reads environment variable
+// falling back to default value when variable is not set.
+func stringFromEnv(key, def string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+
+	return def
+}
+
+// durationFromEnv parses duration from environment variable
+// falling back to default value when variable is not set.
+func durationFromEnv(key string, def time.Duration) (time.Duration, error) {
+	val := os.Getenv(key)
+	if val == "" {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s duration %q: %w", key, val, err)
+	}
+
+	return d, nil
+}
+
+// boolFromEnv parses boolean from environment variable
+// falling back to default value when variable is not set.
+func boolFromEnv(key string, def bool) (bool, error) {
+	val := os.Getenv(key)
+	if val == "" {
+		return def, nil
+	}
+
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s boolean %q: %w", key, val, err)
+	}
+
+	return b, nil
+}
+
+// int64FromEnv parses integer from environment variable
+// falling back to default value when variable is not set.
+func int64FromEnv(key string, def int64) (int64, error) {
+	val := os.Getenv(key)
+	if val == "" {
+		return def, nil
+	}
+
+	n, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s integer %q: %w", key, val, err)
+	}
+
+	return n, nil
+}
+
+// tlsFromEnv reads TLS options, minimal version is written as "1.2" or "1.3".
+func tlsFromEnv() (TLS, error) {
+	minVersion := uint16(tls.VersionTLS12)
+
+	switch val := os.Getenv("TLS_MIN_VERSION"); val {
+	case "", "1.2":
+	case "1.3":
+		minVersion = tls.VersionTLS13
+	default:
+		return TLS{}, fmt.Errorf("\"%v\" is not allowed TLS version", val)
+	}
+
+	selfSigned, err := boolFromEnv("TLS_SELF_SIGNED", false)
+	if err != nil {
+		return TLS{}, err
+	}
+
+	http2, err := boolFromEnv("HTTP2_ENABLED", true)
+	if err != nil {
+		return TLS{}, err
+	}
+
+	reloadInterval, err := durationFromEnv("TLS_RELOAD_INTERVAL", defaultTLSReload)
+	if err != nil {
+		return TLS{}, err
+	}
+
+	return TLS{
+		CertFile:       os.Getenv("TLS_CERT_FILE"),
+		KeyFile:        os.Getenv("TLS_KEY_FILE"),
+		MinVersion:     minVersion,
+		SelfSigned:     selfSigned,
+		HTTP2:          http2,
+		ReloadInterval: reloadInterval,
+	}, nil
+}
+
+// listFromEnv splits comma separated environment variable
+// skipping empty items.
+func listFromEnv(key string) []string {
+	var list []string
+
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+// rateLimitFromEnv reads default rate, rates of routes listed
+// as "METHOD /path:rate" separated by commas and trusted proxies
+// listed as IP addresses or CIDR prefixes separated by commas.
+func rateLimitFromEnv() (RateLimit, error) {
+	def, err := parseRate(os.Getenv("RATE_LIMIT_DEFAULT"))
+	if err != nil {
+		return RateLimit{}, fmt.Errorf("invalid RATE_LIMIT_DEFAULT: %w", err)
+	}
+
+	routes := make(map[string]Rate)
+
+	for _, item := range listFromEnv("RATE_LIMIT_ROUTES") {
+		i := strings.LastIndex(item, ":")
+		if i < 0 {
+			return RateLimit{}, fmt.Errorf("invalid RATE_LIMIT_ROUTES item %q: expected METHOD /path:rate", item)
+		}
+
+		rate, err := parseRate(item[i+1:])
+		if err != nil {
+			return RateLimit{}, fmt.Errorf("invalid RATE_LIMIT_ROUTES item %q: %w", item, err)
+		}
+
+		routes[strings.Join(strings.Fields(item[:i]), " ")] = rate
+	}
+
+	var proxies []netip.Prefix
+
+	for _, item := range listFromEnv("TRUSTED_PROXIES") {
+		prefix, err := parsePrefix(item)
+		if err != nil {
+			return RateLimit{}, fmt.Errorf("invalid TRUSTED_PROXIES item %q: %w", item, err)
+		}
+
+		proxies = append(proxies, prefix)
+	}
+
+	return RateLimit{Default: def, Routes: routes, TrustedProxies: proxies}, nil
+}
+
+// parseRate parses rate written as "requests/period", e.g. "10/1m".
+// Empty value means no limit.
+func parseRate(val string) (Rate, error) {
+	if val == "" {
+		return Rate{}, nil
+	}
+
+	requests, period, found := strings.Cut(val, "/")
+	if !found {
+		return Rate{}, fmt.Errorf("rate %q is not in requests/period format", val)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Rate{}, fmt.Errorf("rate %q has to allow positive number of requests", val)
+	}
+
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Rate{}, fmt.Errorf("rate %q has to have positive period", val)
+	}
+
+	return Rate{Requests: n, Period: d}, nil
+}
+
+// parsePrefix parses CIDR prefix, single address is treated as prefix of full length.
+func parsePrefix(val string) (netip.Prefix, error) {
+	if strings.Contains(val, "/") {
+		prefix, err := netip.ParsePrefix(val)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("error parsing prefix: %w", err)
+		}
+
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(val)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("error parsing address: %w", err)
+	}
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
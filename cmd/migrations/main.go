@@ -2,11 +2,13 @@ package main
 
 import (
 	"database/sql"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 
 	"github.com/go-devs-ua/octagon/app/repository/pg"
+	"github.com/go-devs-ua/octagon/app/repository/sqlite"
 	"github.com/go-devs-ua/octagon/cfg"
 	"github.com/go-devs-ua/octagon/lgr"
 	_ "github.com/lib/pq"
@@ -15,9 +17,10 @@ import (
 )
 
 const (
-	up           = "up"
-	down         = "down"
-	migrationDir = "./migration"
+	up                 = "up"
+	down               = "down"
+	migrationDir       = "./migration"
+	sqliteMigrationDir = "./migration/sqlite"
 )
 
 func main() {
@@ -35,7 +38,7 @@ func main() {
 		return
 	}
 
-	db, err := pg.ConnectDB(config.DB)
+	db, err := connectDB(config.DB)
 	if err != nil {
 		logger.Errorf("%+v", err)
 
@@ -51,16 +54,33 @@ func main() {
 		return
 	}
 
-	if err := migrateDB(db, logger, *direction); err != nil {
+	if err := migrateDB(db, config.DB.Driver, logger, *direction); err != nil {
 		logger.Errorf("Failed making migrations: %v", err)
 	}
 }
 
-// MigrateDB executes migrations.
-func migrateDB(db *sql.DB, logger *lgr.Logger, direction string) error {
+// connectDB connects to database of configured driver.
+func connectDB(config cfg.DB) (*sql.DB, error) {
+	switch config.Driver {
+	case cfg.SQLiteDriver:
+		return sqlite.ConnectDB(config)
+	case cfg.MemoryDriver:
+		return nil, errors.New("in-memory repository does not need migrations")
+	default:
+		return pg.ConnectDB(config)
+	}
+}
+
+// MigrateDB executes migrations of given driver.
+func migrateDB(db *sql.DB, driver string, logger *lgr.Logger, direction string) error {
 	migrations := &migrate.FileMigrationSource{
 		Dir: migrationDir,
 	}
+	dialect := "postgres"
+
+	if driver == cfg.SQLiteDriver {
+		migrations.Dir, dialect = sqliteMigrationDir, "sqlite3"
+	}
 
 	var dir migrate.MigrationDirection
 	if direction == down {
@@ -69,7 +89,7 @@ func migrateDB(db *sql.DB, logger *lgr.Logger, direction string) error {
 
 	logger.Infof("Starting applying migrations '%s'...", direction)
 
-	n, err := migrate.Exec(db, "postgres", migrations, dir)
+	n, err := migrate.Exec(db, dialect, migrations, dir)
 	if err != nil {
 		return fmt.Errorf("migration up failed: %w", err)
 	}
@@ -3,14 +3,36 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
+	"github.com/go-devs-ua/octagon/app/metrics"
+	"github.com/go-devs-ua/octagon/app/notifier"
+	"github.com/go-devs-ua/octagon/app/repository/memory"
 	"github.com/go-devs-ua/octagon/app/repository/pg"
+	"github.com/go-devs-ua/octagon/app/repository/sqlcheck"
+	"github.com/go-devs-ua/octagon/app/repository/sqlite"
 	"github.com/go-devs-ua/octagon/app/transport/rest"
 	"github.com/go-devs-ua/octagon/app/usecase"
+	"github.com/go-devs-ua/octagon/app/worker"
 	"github.com/go-devs-ua/octagon/cfg"
 	"github.com/go-devs-ua/octagon/lgr"
+	"github.com/go-devs-ua/octagon/pkg/hash"
+	"github.com/go-devs-ua/octagon/pkg/ratelimit"
+	"github.com/go-devs-ua/octagon/pkg/token"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Directories migrations are read from when checking readiness.
+const (
+	migrationDir       = "./migration"
+	sqliteMigrationDir = "./migration/sqlite"
 )
 
 func main() {
@@ -33,25 +55,77 @@ func Run() error {
 
 	defer logger.Flush()
 
-	db, err := pg.ConnectDB(config.DB)
+	repo, db, err := newRepo(config.DB, logger)
 	if err != nil {
-		logger.Errorf("%+v", err)
+		return err
+	}
 
-		return fmt.Errorf("error connecting to database on host: %s, port: %s, with error: %w", config.DB.Host, config.DB.Port, err)
+	if db != nil {
+		defer closeDB(db, logger)
 	}
 
-	repo := pg.NewRepo(db)
+	instruments := metrics.New()
+	if db != nil {
+		if err := instruments.RegisterDB(db, config.DB.Driver); err != nil {
+			return err
+		}
+	}
 
-	logger.Infof("Connection to database successfully created")
+	hasher, err := hash.New(config.PasswordHasher)
+	if err != nil {
+		return fmt.Errorf("failed to create password hasher: %w", err)
+	}
+
+	tokens, err := newTokenManager(config.Auth)
+	if err != nil {
+		return fmt.Errorf("failed to create token manager: %w", err)
+	}
+
+	notifications := newNotifier(config.Notifier, logger)
+	retention := usecase.Retention{
+		RestoreGrace: config.Retention.RestoreGrace,
+		PurgeAfter:   config.Retention.PurgeAfter,
+		Anonymize:    config.Retention.PurgeMode == cfg.AnonymizePurgeMode,
+	}
+	users := usecase.NewUser(repo, hasher, usecase.NewVerification(repo, notifications, config.Auth.VerificationTTL), instruments, retention)
+	ttl := usecase.AuthTTL{
+		Refresh:       config.Auth.RefreshTokenTTL,
+		PasswordReset: config.Auth.PasswordResetTTL,
+	}
+	auth := usecase.NewAuth(users, repo, tokens, notifications, ttl, !config.Auth.AllowUnverified)
 
 	handlers := rest.Handlers{
-		UserHandler: rest.NewUserHandler(usecase.NewUser(repo), logger),
+		UserHandler:   rest.NewUserHandler(users, logger),
+		AuthHandler:   rest.NewAuthHandler(auth, logger),
+		RoleHandler:   rest.NewRoleHandler(usecase.NewRole(users, repo), logger),
+		HealthHandler: rest.NewHealthHandler(newHealth(config, db), logger),
+		Metrics:       instruments,
+		RateLimits:    ratelimit.NewMemory(),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Background jobs are stopped before the database is closed.
+	var jobs sync.WaitGroup
+	defer jobs.Wait()
+
+	jobsCtx, cancelJobs := context.WithCancel(ctx)
+	defer cancelJobs()
+
+	if config.Retention.PurgeInterval > 0 {
+		jobs.Add(1)
+
+		go func() {
+			defer jobs.Done()
+			worker.NewPurger(users, config.Retention.PurgeInterval, logger).Run(jobsCtx)
+		}()
 	}
 
 	srv := rest.NewServer(config, handlers, logger)
-	logger.Infof("Server starts on port:%s", config.Server.Port)
+	logger.Infof("Server starts on port:%s, TLS enabled: %t", config.Server.Port, config.Server.TLS.Enabled())
 
-	if err := srv.Run(); err != nil {
+	if err := srv.Run(ctx); err != nil {
 		logger.Errorf("Failed loading server: %+v", err)
 
 		return fmt.Errorf("error loading server: %w", err)
@@ -59,3 +133,90 @@ func Run() error {
 
 	return nil
 }
+
+func closeDB(db *sql.DB, logger *lgr.Logger) {
+	if err := db.Close(); err != nil {
+		logger.Errorf("Failed closing database: %+v", err)
+
+		return
+	}
+
+	logger.Infof("Database connection closed")
+}
+
+// repository combines all repositories usecases depend on.
+type repository interface {
+	usecase.Repository
+	usecase.AuthRepository
+}
+
+// newRepo creates repository of configured driver along with its database handle,
+// which is nil for in-memory repository.
+func newRepo(config cfg.DB, logger *lgr.Logger) (repository, *sql.DB, error) {
+	switch config.Driver {
+	case cfg.MemoryDriver:
+		logger.Infof("Using in-memory repository, data will be lost on exit")
+
+		return memory.NewRepo(), nil, nil
+	case cfg.SQLiteDriver:
+		db, err := sqlite.ConnectDB(config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error opening database file %s: %w", config.Path, err)
+		}
+
+		logger.Infof("Database file %s successfully opened", config.Path)
+
+		return sqlite.NewRepo(db, config.QueryTimeout), db, nil
+	}
+
+	db, err := pg.ConnectDB(config)
+	if err != nil {
+		logger.Errorf("%+v", err)
+
+		return nil, nil, fmt.Errorf("error connecting to database on host: %s, port: %s, with error: %w", config.Host, config.Port, err)
+	}
+
+	logger.Infof("Connection to database successfully created")
+
+	return pg.NewRepo(db, config.QueryTimeout), db, nil
+}
+
+// newHealth registers readiness checks of the database,
+// in-memory repository has nothing to check.
+func newHealth(config cfg.Options, db *sql.DB) usecase.Health {
+	health := usecase.NewHealth(config.Server.ReadinessTimeout)
+	if db == nil {
+		return health
+	}
+
+	dir := migrationDir
+	if config.DB.Driver == cfg.SQLiteDriver {
+		dir = sqliteMigrationDir
+	}
+
+	health.Register("database", sqlcheck.Ping(db))
+	health.Register("migrations", sqlcheck.Migrations(db, &migrate.FileMigrationSource{Dir: dir}))
+
+	return health
+}
+
+func newTokenManager(config cfg.Auth) (token.Manager, error) {
+	if config.SigningMethod == cfg.EdDSASigningMethod {
+		key, err := token.LoadEdDSAKey(config.PrivateKeyFile)
+		if err != nil {
+			return token.Manager{}, fmt.Errorf("failed to load private key: %w", err)
+		}
+
+		return token.NewEdDSA(key, config.AccessTokenTTL).WithIssuer(config.Issuer, config.Audience), nil
+	}
+
+	return token.NewHS256([]byte(config.Secret), config.AccessTokenTTL).WithIssuer(config.Issuer, config.Audience), nil
+}
+
+func newNotifier(config cfg.Notifier, logger *lgr.Logger) usecase.Notifier {
+	if config.Kind == cfg.FileNotifier {
+		return notifier.NewFile(config.File)
+	}
+
+	return notifier.NewLog(logger)
+}
@@ -1,7 +1,10 @@
 package lgr
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"syscall"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -29,13 +32,14 @@ func New(logLevel string) (*Logger, error) {
 		OutputPaths:      []string{"stderr"},
 		ErrorOutputPaths: []string{"stderr"},
 		EncoderConfig: zapcore.EncoderConfig{
-			MessageKey:   "message",
-			LevelKey:     "level",
-			EncodeLevel:  zapcore.CapitalLevelEncoder,
-			TimeKey:      "time",
-			EncodeTime:   zapcore.ISO8601TimeEncoder,
-			CallerKey:    "caller",
-			EncodeCaller: zapcore.ShortCallerEncoder,
+			MessageKey:     "message",
+			LevelKey:       "level",
+			EncodeLevel:    zapcore.CapitalLevelEncoder,
+			TimeKey:        "time",
+			EncodeTime:     zapcore.ISO8601TimeEncoder,
+			CallerKey:      "caller",
+			EncodeCaller:   zapcore.ShortCallerEncoder,
+			EncodeDuration: zapcore.StringDurationEncoder,
 		},
 	}
 
@@ -47,9 +51,38 @@ func New(logLevel string) (*Logger, error) {
 	return &Logger{logger.Sugar()}, nil
 }
 
+// With returns child logger adding given key-value pairs to every entry.
+func (l *Logger) With(keyVal ...any) *Logger {
+	return &Logger{l.log.With(keyVal...)}
+}
+
+type ctxKey struct{}
+
+// NewContext returns copy of ctx carrying logger,
+// e.g. request-scoped one.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns logger carried by ctx
+// or logger discarding all entries when there is none.
+func FromContext(ctx context.Context) *Logger {
+	return (&Logger{zap.NewNop().Sugar()}).Ctx(ctx)
+}
+
+// Ctx returns logger carried by ctx or l itself when there is none.
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	if logger, ok := ctx.Value(ctxKey{}).(*Logger); ok {
+		return logger
+	}
+
+	return l
+}
+
 // Flush will flush any buffered log entries.
+// Terminals and pipes can not be synced, so such errors are ignored.
 func (l *Logger) Flush() {
-	if err := l.log.Sync(); err != nil {
+	if err := l.log.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) && !errors.Is(err, syscall.ENOTTY) {
 		l.log.Error(err)
 	}
 }
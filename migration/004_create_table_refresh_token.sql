@@ -0,0 +1,19 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE "refresh_token" (
+    "id" UUID DEFAULT gen_random_uuid() NOT NULL,
+    "user_id" UUID NOT NULL,
+    "token_hash" VARCHAR(64) NOT NULL,
+    "expires_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+    "revoked_at" TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "unique_refresh_token_hash" UNIQUE("token_hash"),
+    CONSTRAINT "fk_refresh_token_user" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE CASCADE
+);
+
+CREATE INDEX "idx_refresh_token_user_id" ON "refresh_token"("user_id");
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE "refresh_token";
@@ -0,0 +1,54 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE "role" (
+    "name" VARCHAR(64) NOT NULL,
+    "description" VARCHAR(255) NOT NULL DEFAULT '',
+    PRIMARY KEY ("name")
+);
+
+CREATE TABLE "permission" (
+    "name" VARCHAR(64) NOT NULL,
+    "description" VARCHAR(255) NOT NULL DEFAULT '',
+    PRIMARY KEY ("name")
+);
+
+CREATE TABLE "role_permission" (
+    "role" VARCHAR(64) NOT NULL,
+    "permission" VARCHAR(64) NOT NULL,
+    PRIMARY KEY ("role", "permission"),
+    CONSTRAINT "fk_role_permission_role" FOREIGN KEY ("role") REFERENCES "role"("name") ON DELETE CASCADE,
+    CONSTRAINT "fk_role_permission_permission" FOREIGN KEY ("permission") REFERENCES "permission"("name") ON DELETE CASCADE
+);
+
+CREATE TABLE "user_role" (
+    "user_id" UUID NOT NULL,
+    "role" VARCHAR(64) NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY ("user_id", "role"),
+    CONSTRAINT "fk_user_role_user" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_user_role_role" FOREIGN KEY ("role") REFERENCES "role"("name") ON DELETE CASCADE
+);
+
+INSERT INTO "role" ("name", "description") VALUES
+    ('admin', 'Back-office staff moderating accounts'),
+    ('member', 'Regular user');
+
+INSERT INTO "permission" ("name", "description") VALUES
+    ('users:list', 'List all users'),
+    ('users:manage', 'Act on accounts of other users'),
+    ('roles:manage', 'Assign and revoke roles');
+
+INSERT INTO "role_permission" ("role", "permission") VALUES
+    ('admin', 'users:list'),
+    ('admin', 'users:manage'),
+    ('admin', 'roles:manage');
+
+INSERT INTO "user_role" ("user_id", "role")
+    SELECT "id", 'member' FROM "user";
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE "user_role";
+DROP TABLE "role_permission";
+DROP TABLE "permission";
+DROP TABLE "role";
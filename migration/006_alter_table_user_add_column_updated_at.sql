@@ -0,0 +1,28 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE "user"
+    ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+
+UPDATE "user" SET updated_at = created_at;
+
+-- +migrate StatementBegin
+CREATE FUNCTION set_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+CREATE TRIGGER "trg_user_updated_at"
+    BEFORE UPDATE ON "user"
+    FOR EACH ROW
+    EXECUTE FUNCTION set_updated_at();
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TRIGGER "trg_user_updated_at" ON "user";
+DROP FUNCTION set_updated_at();
+
+ALTER TABLE "user"
+    DROP COLUMN updated_at;
@@ -0,0 +1,20 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE "user_token" (
+    "id" UUID DEFAULT gen_random_uuid() NOT NULL,
+    "user_id" UUID NOT NULL,
+    "purpose" VARCHAR(32) NOT NULL,
+    "token_hash" VARCHAR(64) NOT NULL,
+    "expires_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+    "used_at" TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "unique_user_token_hash" UNIQUE("token_hash"),
+    CONSTRAINT "fk_user_token_user" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE CASCADE
+);
+
+CREATE INDEX "idx_user_token_user_id" ON "user_token"("user_id");
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE "user_token";
@@ -0,0 +1,10 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE "user" ADD COLUMN "verified_at" TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+-- Accounts created before verification was introduced are trusted.
+UPDATE "user" SET "verified_at" = "created_at";
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE "user" DROP COLUMN "verified_at";
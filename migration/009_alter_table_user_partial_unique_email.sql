@@ -0,0 +1,13 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE "user" DROP CONSTRAINT "unique_user_email";
+
+-- Email of deleted user can be taken by new user.
+CREATE UNIQUE INDEX "unique_user_email" ON "user"("email") WHERE "deleted_at" IS NULL;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+-- Rolling back fails if email of deleted user was taken again.
+DROP INDEX "unique_user_email";
+
+ALTER TABLE "user" ADD CONSTRAINT "unique_user_email" UNIQUE("email");
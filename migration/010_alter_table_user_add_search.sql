@@ -0,0 +1,26 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE EXTENSION IF NOT EXISTS "pg_trgm";
+
+-- Full-text search finds whole words, trigram search finds their parts.
+ALTER TABLE "user"
+    ADD COLUMN "search_text" TEXT GENERATED ALWAYS AS (
+        lower("first_name" || ' ' || COALESCE("last_name", '') || ' ' || "email")
+    ) STORED,
+    ADD COLUMN "search_vector" TSVECTOR GENERATED ALWAYS AS (
+        to_tsvector('simple', "first_name" || ' ' || COALESCE("last_name", '') || ' ' || "email")
+    ) STORED;
+
+CREATE INDEX "idx_user_search_vector" ON "user" USING GIN ("search_vector");
+CREATE INDEX "idx_user_search_text" ON "user" USING GIN ("search_text" gin_trgm_ops);
+CREATE INDEX "idx_user_created_at" ON "user"("created_at");
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX "idx_user_created_at";
+DROP INDEX "idx_user_search_text";
+DROP INDEX "idx_user_search_vector";
+
+ALTER TABLE "user"
+    DROP COLUMN "search_vector",
+    DROP COLUMN "search_text";
@@ -0,0 +1,7 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE "user" ADD COLUMN "anonymized_at" TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE "user" DROP COLUMN "anonymized_at";
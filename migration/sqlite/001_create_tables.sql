@@ -0,0 +1,106 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+-- SQLite has neither UUID generation nor timestamp types, so ids are generated by the app
+-- and times are kept as fixed width UTC text, e.g. 2006-01-02T15:04:05.000Z, which sorts chronologically.
+CREATE TABLE "user" (
+    "id" TEXT NOT NULL,
+    "first_name" TEXT NOT NULL,
+    "last_name" TEXT,
+    "email" TEXT NOT NULL,
+    "password" TEXT NOT NULL,
+    "created_at" TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    "updated_at" TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    "verified_at" TEXT DEFAULT NULL,
+    "deleted_at" TEXT DEFAULT NULL,
+    "anonymized_at" TEXT DEFAULT NULL,
+    "search_text" TEXT GENERATED ALWAYS AS (
+        lower("first_name" || ' ' || COALESCE("last_name", '') || ' ' || "email")
+    ) VIRTUAL,
+    PRIMARY KEY ("id")
+);
+
+-- Email of deleted user can be taken by new user.
+CREATE UNIQUE INDEX "unique_user_email" ON "user"("email") WHERE "deleted_at" IS NULL;
+CREATE INDEX "idx_user_created_at" ON "user"("created_at");
+
+CREATE TABLE "refresh_token" (
+    "id" TEXT NOT NULL,
+    "user_id" TEXT NOT NULL,
+    "token_hash" TEXT NOT NULL,
+    "expires_at" TEXT NOT NULL,
+    "revoked_at" TEXT DEFAULT NULL,
+    "created_at" TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "unique_refresh_token_hash" UNIQUE("token_hash"),
+    CONSTRAINT "fk_refresh_token_user" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE CASCADE
+);
+
+CREATE INDEX "idx_refresh_token_user_id" ON "refresh_token"("user_id");
+
+CREATE TABLE "role" (
+    "name" TEXT NOT NULL,
+    "description" TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY ("name")
+);
+
+CREATE TABLE "permission" (
+    "name" TEXT NOT NULL,
+    "description" TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY ("name")
+);
+
+CREATE TABLE "role_permission" (
+    "role" TEXT NOT NULL,
+    "permission" TEXT NOT NULL,
+    PRIMARY KEY ("role", "permission"),
+    CONSTRAINT "fk_role_permission_role" FOREIGN KEY ("role") REFERENCES "role"("name") ON DELETE CASCADE,
+    CONSTRAINT "fk_role_permission_permission" FOREIGN KEY ("permission") REFERENCES "permission"("name") ON DELETE CASCADE
+);
+
+CREATE TABLE "user_role" (
+    "user_id" TEXT NOT NULL,
+    "role" TEXT NOT NULL,
+    "created_at" TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    PRIMARY KEY ("user_id", "role"),
+    CONSTRAINT "fk_user_role_user" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_user_role_role" FOREIGN KEY ("role") REFERENCES "role"("name") ON DELETE CASCADE
+);
+
+CREATE TABLE "user_token" (
+    "id" TEXT NOT NULL,
+    "user_id" TEXT NOT NULL,
+    "purpose" TEXT NOT NULL,
+    "token_hash" TEXT NOT NULL,
+    "expires_at" TEXT NOT NULL,
+    "used_at" TEXT DEFAULT NULL,
+    "created_at" TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "unique_user_token_hash" UNIQUE("token_hash"),
+    CONSTRAINT "fk_user_token_user" FOREIGN KEY ("user_id") REFERENCES "user"("id") ON DELETE CASCADE
+);
+
+CREATE INDEX "idx_user_token_user_id" ON "user_token"("user_id");
+
+INSERT INTO "role" ("name", "description") VALUES
+    ('admin', 'Back-office staff moderating accounts'),
+    ('member', 'Regular user');
+
+INSERT INTO "permission" ("name", "description") VALUES
+    ('users:list', 'List all users'),
+    ('users:manage', 'Act on accounts of other users'),
+    ('roles:manage', 'Assign and revoke roles');
+
+INSERT INTO "role_permission" ("role", "permission") VALUES
+    ('admin', 'users:list'),
+    ('admin', 'users:manage'),
+    ('admin', 'roles:manage');
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE "user_token";
+DROP TABLE "user_role";
+DROP TABLE "role_permission";
+DROP TABLE "permission";
+DROP TABLE "role";
+DROP TABLE "refresh_token";
+DROP TABLE "user";
@@ -0,0 +1,161 @@
+// Package cert provides TLS certificates for the server: either loaded
+// from files, which are reloaded once they change on disk,
+// or self-signed ones generated in memory for development.
+package cert
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const serialNumberBits = 128
+
+// SelfSigned generates certificate for hosts, which are either DNS names or IP addresses.
+// Clients do not trust it, so it is meant for development only.
+func SelfSigned(hosts []string, validFor time.Duration) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("error generating key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), serialNumberBits))
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("error generating serial number: %w", err)
+	}
+
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Octagon development"}},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(validFor),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("error creating certificate: %w", err)
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("error parsing certificate: %w", err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, nil
+}
+
+// Reloader serves certificate loaded from files
+// and replaces it once any of the files is modified.
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	certMod time.Time
+	keyMod  time.Time
+}
+
+// NewReloader loads certificate from PEM encoded files.
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetCertificate returns current certificate, so it fits tls.Config.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// Reload loads certificate if files were modified since the last load
+// and reports whether certificate was replaced. Current certificate
+// is kept on failure, e.g. when only one of the files is rewritten yet.
+func (r *Reloader) Reload() (bool, error) {
+	certMod, err := modTime(r.certFile)
+	if err != nil {
+		return false, err
+	}
+
+	keyMod, err := modTime(r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	unchanged := r.cert != nil && certMod.Equal(r.certMod) && keyMod.Equal(r.keyMod)
+	r.mu.RUnlock()
+
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("error loading key pair: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert, r.certMod, r.keyMod = &cert, certMod, keyMod
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+func modTime(name string) (time.Time, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error checking %s: %w", name, err)
+	}
+
+	return info.ModTime(), nil
+}
+
+// Watch reloads certificate every interval until ctx is done.
+// Outcome of each reload which either replaced certificate
+// or failed is passed to report, nil meaning success.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration, report func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if reloaded, err := r.Reload(); reloaded || err != nil {
+			report(err)
+		}
+	}
+}
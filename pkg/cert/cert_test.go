@@ -0,0 +1,102 @@
+package cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writePair writes certificate for host along with its key as PEM files
+// and sets their modification time to mod.
+func writePair(t *testing.T, certFile, keyFile, host string, mod time.Time) {
+	t.Helper()
+
+	c, err := SelfSigned([]string{host}, time.Hour)
+	require.NoError(t, err)
+
+	key, err := x509.MarshalECPrivateKey(c.PrivateKey.(*ecdsa.PrivateKey))
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Certificate[0]}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: key}), 0o600))
+	require.NoError(t, os.Chtimes(certFile, mod, mod))
+	require.NoError(t, os.Chtimes(keyFile, mod, mod))
+}
+
+func dnsNames(t *testing.T, r *Reloader) []string {
+	t.Helper()
+
+	c, err := r.GetCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(c.Certificate[0])
+	require.NoError(t, err)
+
+	return leaf.DNSNames
+}
+
+func TestSelfSigned(t *testing.T) {
+	c, err := SelfSigned([]string{"localhost", "127.0.0.1", "example.com"}, time.Hour)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"localhost", "example.com"}, c.Leaf.DNSNames)
+	require.Len(t, c.Leaf.IPAddresses, 1)
+	require.True(t, c.Leaf.IPAddresses[0].Equal(net.ParseIP("127.0.0.1")))
+	require.WithinDuration(t, time.Now().Add(time.Hour), c.Leaf.NotAfter, time.Minute)
+	require.NoError(t, c.Leaf.VerifyHostname("example.com"))
+	require.Error(t, c.Leaf.VerifyHostname("other.com"))
+}
+
+func TestReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	mod := time.Now().Add(-time.Hour)
+
+	writePair(t, certFile, keyFile, "old.example.com", mod)
+
+	r, err := NewReloader(certFile, keyFile)
+	require.NoError(t, err)
+	require.Equal(t, []string{"old.example.com"}, dnsNames(t, r))
+
+	reloaded, err := r.Reload()
+	require.NoError(t, err)
+	require.False(t, reloaded, "unchanged files are not reloaded")
+
+	mod = mod.Add(time.Minute)
+	writePair(t, certFile, keyFile, "new.example.com", mod)
+
+	reloaded, err = r.Reload()
+	require.NoError(t, err)
+	require.True(t, reloaded)
+	require.Equal(t, []string{"new.example.com"}, dnsNames(t, r))
+
+	// Only certificate is rewritten, so it does not match the key.
+	mod = mod.Add(time.Minute)
+	writePair(t, certFile, filepath.Join(dir, "other.pem"), "next.example.com", mod)
+
+	reloaded, err = r.Reload()
+	require.Error(t, err)
+	require.False(t, reloaded)
+	require.Equal(t, []string{"new.example.com"}, dnsNames(t, r), "previous certificate is kept on failure")
+
+	require.NoError(t, os.Remove(keyFile))
+
+	_, err = r.Reload()
+	require.Error(t, err)
+	require.Equal(t, []string{"new.example.com"}, dnsNames(t, r), "previous certificate is kept on failure")
+}
+
+func TestNewReloader(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewReloader(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+	require.Error(t, err)
+}
@@ -0,0 +1,119 @@
+package hash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Default argon2id parameters, as recommended by OWASP.
+const (
+	argon2IDPrefix     = "$argon2id$"
+	argon2DefaultTime  = 2
+	argon2DefaultMem   = 19 * 1024
+	argon2DefaultPar   = 1
+	argon2DefaultKey   = 32
+	argon2DefaultSalt  = 16
+	argon2EncodedParts = 6
+)
+
+// Argon2IDHasher implements PasswordHasher using argon2id
+// and encodes hashes in PHC string format:
+// $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<key>.
+type Argon2IDHasher struct {
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+	KeyLen  uint32
+	SaltLen uint32
+}
+
+// NewArgon2ID returns Argon2IDHasher with default parameters.
+func NewArgon2ID() Argon2IDHasher {
+	return Argon2IDHasher{
+		Time:    argon2DefaultTime,
+		Memory:  argon2DefaultMem,
+		Threads: argon2DefaultPar,
+		KeyLen:  argon2DefaultKey,
+		SaltLen: argon2DefaultSalt,
+	}
+}
+
+// Hash returns argon2id hash of the password with random salt.
+func (h Argon2IDHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("error generating salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Time, h.Memory, h.Threads, h.KeyLen)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2IDPrefix, argon2.Version, h.Memory, h.Time, h.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify compares password with encoded argon2id hash
+// using parameters stored in the hash itself.
+func (h Argon2IDHasher) Verify(password, encoded string) (bool, error) {
+	params, salt, key, err := decodeArgon2ID(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, params.KeyLen)
+
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// NeedsRehash reports whether encoded is not argon2id hash
+// or was produced with other parameters.
+func (h Argon2IDHasher) NeedsRehash(encoded string) bool {
+	params, salt, _, err := decodeArgon2ID(encoded)
+	if err != nil {
+		return true
+	}
+
+	params.SaltLen = uint32(len(salt))
+
+	return params != h
+}
+
+func decodeArgon2ID(encoded string) (Argon2IDHasher, []byte, []byte, error) {
+	var params Argon2IDHasher
+
+	// Leading "$" gives an empty first part.
+	parts := strings.Split(encoded, "$")
+	if len(parts) != argon2EncodedParts || parts[1] != Argon2ID {
+		return params, nil, nil, ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("%w: unsupported argon2 version", ErrMalformedHash)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return params, nil, nil, fmt.Errorf("%w: %v", ErrMalformedHash, err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("%w: %v", ErrMalformedHash, err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("%w: %v", ErrMalformedHash, err)
+	}
+
+	params.KeyLen = uint32(len(key))
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,76 @@
+package hash
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BcryptMaxPasswordBytes is the longest password bcrypt takes into account,
+// the rest of longer ones would be silently ignored.
+const BcryptMaxPasswordBytes = 72
+
+// BcryptHasher implements PasswordHasher using bcrypt,
+// which already encodes algorithm version and cost
+// in its modular crypt format: $2a$<cost>$<salt+key>.
+// Passwords longer than BcryptMaxPasswordBytes are rejected.
+type BcryptHasher struct {
+	Cost int
+}
+
+// NewBcrypt returns BcryptHasher with default cost.
+func NewBcrypt() BcryptHasher {
+	return BcryptHasher{Cost: bcrypt.DefaultCost}
+}
+
+// Hash returns bcrypt hash of the password.
+func (h BcryptHasher) Hash(password string) (string, error) {
+	if len(password) > BcryptMaxPasswordBytes {
+		return "", fmt.Errorf("%w: bcrypt takes at most %d bytes", ErrPasswordTooLong, BcryptMaxPasswordBytes)
+	}
+
+	b, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", fmt.Errorf("error generating bcrypt hash: %w", err)
+	}
+
+	return string(b), nil
+}
+
+// Verify compares password with encoded bcrypt hash.
+func (h BcryptHasher) Verify(password, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if err == nil {
+		return true, nil
+	}
+
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+
+	return false, fmt.Errorf("%w: %v", ErrMalformedHash, err)
+}
+
+// NeedsRehash reports whether encoded is not bcrypt hash
+// or was produced with other cost.
+func (h BcryptHasher) NeedsRehash(encoded string) bool {
+	if !isBcrypt(encoded) {
+		return true
+	}
+
+	cost, err := bcrypt.Cost([]byte(encoded))
+
+	return err != nil || cost != h.Cost
+}
+
+func isBcrypt(encoded string) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		if strings.HasPrefix(encoded, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
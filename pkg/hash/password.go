@@ -0,0 +1,75 @@
+// Package hash holds on hashing primitives used across the app.
+package hash
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// List of supported password hashing algorithms.
+const (
+	Argon2ID = "argon2id"
+	Bcrypt   = "bcrypt"
+)
+
+var (
+	ErrUnknownAlgorithm = errors.New("unknown password hashing algorithm")
+	ErrMalformedHash    = errors.New("malformed password hash")
+	ErrPasswordTooLong  = errors.New("password is too long")
+)
+
+// PasswordHasher hashes passwords into self-describing
+// encoded strings that carry algorithm and its parameters.
+type PasswordHasher interface {
+	// Hash returns salted encoded hash of the password
+	// or ErrPasswordTooLong if algorithm can not take all of it into account.
+	Hash(password string) (string, error)
+	// Verify reports whether password matches encoded hash.
+	Verify(password, encoded string) (bool, error)
+	// NeedsRehash reports whether encoded hash was produced
+	// with algorithm or parameters different from the current ones.
+	NeedsRehash(encoded string) bool
+}
+
+// New returns PasswordHasher for given algorithm with default parameters.
+func New(algorithm string) (PasswordHasher, error) {
+	switch strings.ToLower(algorithm) {
+	case Argon2ID, "":
+		return NewArgon2ID(), nil
+	case Bcrypt:
+		return NewBcrypt(), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, algorithm)
+	}
+}
+
+// Verify checks password against encoded hash produced by any supported algorithm,
+// including legacy unsalted SHA-256, and reports whether hash has to be
+// upgraded to the preferred hasher.
+func Verify(preferred PasswordHasher, password, encoded string) (match, rehash bool, err error) {
+	verifier, err := verifierFor(encoded)
+	if err != nil {
+		return false, false, err
+	}
+
+	match, err = verifier.Verify(password, encoded)
+	if err != nil || !match {
+		return false, false, err
+	}
+
+	return true, preferred.NeedsRehash(encoded), nil
+}
+
+func verifierFor(encoded string) (PasswordHasher, error) {
+	switch {
+	case strings.HasPrefix(encoded, argon2IDPrefix):
+		return NewArgon2ID(), nil
+	case isBcrypt(encoded):
+		return NewBcrypt(), nil
+	case isSHA256(encoded):
+		return legacySHA256{}, nil
+	default:
+		return nil, ErrUnknownAlgorithm
+	}
+}
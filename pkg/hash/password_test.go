@@ -0,0 +1,123 @@
+package hash
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	const password = "Qwerty123!"
+
+	argon, err := NewArgon2ID().Hash(password)
+	require.NoError(t, err)
+
+	weakArgon := NewArgon2ID()
+	weakArgon.Time = 1
+	weakArgonHash, err := weakArgon.Hash(password)
+	require.NoError(t, err)
+
+	bcryptHash, err := NewBcrypt().Hash(password)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		preferred   PasswordHasher
+		password    string
+		encoded     string
+		expMatch    bool
+		expRehash   bool
+		expectedErr error
+	}{
+		"argon2id": {
+			preferred: NewArgon2ID(),
+			password:  password,
+			encoded:   argon,
+			expMatch:  true,
+		},
+		"argon2id_wrong_password": {
+			preferred: NewArgon2ID(),
+			password:  "wrong",
+			encoded:   argon,
+		},
+		"argon2id_outdated_params": {
+			preferred: NewArgon2ID(),
+			password:  password,
+			encoded:   weakArgonHash,
+			expMatch:  true,
+			expRehash: true,
+		},
+		"bcrypt": {
+			preferred: NewBcrypt(),
+			password:  password,
+			encoded:   bcryptHash,
+			expMatch:  true,
+		},
+		"bcrypt_to_argon2id": {
+			preferred: NewArgon2ID(),
+			password:  password,
+			encoded:   bcryptHash,
+			expMatch:  true,
+			expRehash: true,
+		},
+		"legacy_sha256": {
+			preferred: NewArgon2ID(),
+			password:  password,
+			encoded:   SHA256(password),
+			expMatch:  true,
+			expRehash: true,
+		},
+		"legacy_sha256_wrong_password": {
+			preferred: NewArgon2ID(),
+			password:  "wrong",
+			encoded:   SHA256(password),
+		},
+		"unknown": {
+			preferred:   NewArgon2ID(),
+			password:    password,
+			encoded:     "$md5$whatever",
+			expectedErr: ErrUnknownAlgorithm,
+		},
+		"malformed_argon2id": {
+			preferred:   NewArgon2ID(),
+			password:    password,
+			encoded:     "$argon2id$v=19$m=a,t=b,p=c$salt$key",
+			expectedErr: ErrMalformedHash,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			match, rehash, err := Verify(tt.preferred, tt.password, tt.encoded)
+
+			require.ErrorIs(t, err, tt.expectedErr)
+			require.Equal(t, tt.expMatch, match)
+			require.Equal(t, tt.expRehash, rehash)
+		})
+	}
+}
+
+func TestArgon2IDHasher_Hash(t *testing.T) {
+	h := NewArgon2ID()
+
+	first, err := h.Hash("password")
+	require.NoError(t, err)
+
+	second, err := h.Hash("password")
+	require.NoError(t, err)
+
+	require.NotEqual(t, first, second, "hashes of the same password must be salted")
+	require.Regexp(t, `^\$argon2id\$v=19\$m=19456,t=2,p=1\$[A-Za-z0-9+/]+\$[A-Za-z0-9+/]+$`, first)
+	require.False(t, h.NeedsRehash(first))
+}
+
+func TestBcryptHasher_Hash(t *testing.T) {
+	h := NewBcrypt()
+
+	encoded, err := h.Hash(strings.Repeat("a", BcryptMaxPasswordBytes))
+	require.NoError(t, err)
+	require.False(t, h.NeedsRehash(encoded))
+
+	_, err = h.Hash(strings.Repeat("a", BcryptMaxPasswordBytes+1))
+	require.ErrorIs(t, err, ErrPasswordTooLong, "bcrypt would ignore the rest of password")
+}
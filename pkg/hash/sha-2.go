@@ -2,6 +2,8 @@ package hash
 
 import (
 	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
 )
 
@@ -11,3 +13,26 @@ func SHA256(someString string) string {
 
 	return fmt.Sprintf("%x", h)
 }
+
+// legacySHA256 verifies unsalted SHA-256 password digests
+// stored before salted hashers were introduced.
+// It is never used to produce new hashes.
+type legacySHA256 struct{}
+
+func (legacySHA256) Hash(string) (string, error) {
+	return "", fmt.Errorf("%w: sha256 is supported for verification only", ErrUnknownAlgorithm)
+}
+
+func (legacySHA256) Verify(password, encoded string) (bool, error) {
+	return subtle.ConstantTimeCompare([]byte(SHA256(password)), []byte(encoded)) == 1, nil
+}
+
+func (legacySHA256) NeedsRehash(string) bool {
+	return true
+}
+
+func isSHA256(encoded string) bool {
+	b, err := hex.DecodeString(encoded)
+
+	return err == nil && len(b) == sha256.Size
+}
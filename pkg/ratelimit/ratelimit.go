@@ -0,0 +1,125 @@
+// Package ratelimit takes care of limiting rate of requests
+// with token buckets kept per key, e.g. per client.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval tells how often buckets refilled to full capacity are dropped.
+const sweepInterval = time.Minute
+
+// Limit allows Requests per Period. Bucket holds on at most Requests tokens,
+// so the whole limit can be spent at once and is then refilled evenly.
+// Zero Limit does not limit anything.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Unlimited reports whether limit does not limit anything.
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Period <= 0
+}
+
+// perSecond returns number of tokens added to the bucket each second.
+func (l Limit) perSecond() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Status is result of taking token from the bucket.
+// Reset tells when bucket is full again and
+// RetryAfter when the next request is allowed if this one was not.
+type Status struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	limit   Limit
+	tokens  float64
+	updated time.Time
+}
+
+// refill adds tokens accumulated since the last update.
+func (b *bucket) refill(now time.Time) {
+	b.tokens = math.Min(float64(b.limit.Requests), b.tokens+now.Sub(b.updated).Seconds()*b.limit.perSecond())
+	b.updated = now
+}
+
+// Memory keeps token buckets in memory, so they are neither
+// shared between instances of the app nor survive restart.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemory will initialise new instance of Memory.
+func NewMemory() *Memory {
+	return &Memory{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Take takes token from the bucket of given key
+// creating full bucket on the first request.
+func (m *Memory) Take(ctx context.Context, key string, limit Limit) (Status, error) {
+	now := m.now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok || b.limit != limit {
+		b = &bucket{limit: limit, tokens: float64(limit.Requests), updated: now}
+		m.buckets[key] = b
+	}
+
+	b.refill(now)
+
+	status := Status{Limit: limit.Requests}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		status.Allowed = true
+	} else {
+		status.RetryAfter = seconds((1 - b.tokens) / limit.perSecond())
+	}
+
+	status.Remaining = int(b.tokens)
+	status.Reset = seconds((float64(limit.Requests) - b.tokens) / limit.perSecond())
+
+	return status, nil
+}
+
+// sweep drops buckets which are full by now, as they are
+// no different from buckets created on the next request.
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+
+	for key, b := range m.buckets {
+		if b.refill(now); b.tokens >= float64(b.limit.Requests) {
+			delete(m.buckets, key)
+		}
+	}
+
+	m.lastSweep = now
+}
+
+// seconds converts fractional seconds into duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
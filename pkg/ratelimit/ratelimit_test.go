@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_Take(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	limit := Limit{Requests: 3, Period: 3 * time.Second}
+
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+
+	for i := 2; i >= 0; i-- {
+		status, err := m.Take(ctx, "client", limit)
+		require.NoError(t, err)
+		require.True(t, status.Allowed)
+		require.Equal(t, i, status.Remaining)
+		require.Equal(t, 3, status.Limit)
+		require.Equal(t, time.Duration(3-i)*time.Second, status.Reset)
+	}
+
+	status, err := m.Take(ctx, "client", limit)
+	require.NoError(t, err)
+	require.False(t, status.Allowed)
+	require.Equal(t, time.Second, status.RetryAfter)
+
+	other, err := m.Take(ctx, "other", limit)
+	require.NoError(t, err)
+	require.True(t, other.Allowed, "buckets of other keys are not affected")
+
+	now = now.Add(500 * time.Millisecond)
+	status, err = m.Take(ctx, "client", limit)
+	require.NoError(t, err)
+	require.False(t, status.Allowed)
+	require.Equal(t, 500*time.Millisecond, status.RetryAfter)
+
+	now = now.Add(500 * time.Millisecond)
+	status, err = m.Take(ctx, "client", limit)
+	require.NoError(t, err)
+	require.True(t, status.Allowed, "token is refilled after period divided by requests")
+	require.Equal(t, 0, status.Remaining)
+}
+
+func TestMemory_sweep(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+
+	_, err := m.Take(ctx, "idle", Limit{Requests: 10, Period: time.Second})
+	require.NoError(t, err)
+	_, err = m.Take(ctx, "busy", Limit{Requests: 10, Period: time.Hour})
+	require.NoError(t, err)
+
+	now = now.Add(sweepInterval)
+	_, err = m.Take(ctx, "new", Limit{Requests: 10, Period: time.Hour})
+	require.NoError(t, err)
+
+	require.NotContains(t, m.buckets, "idle")
+	require.Contains(t, m.buckets, "busy")
+	require.Contains(t, m.buckets, "new")
+}
@@ -0,0 +1,153 @@
+// Package token takes care of issuing and parsing
+// signed access tokens and generating opaque refresh tokens.
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// List of supported signing methods.
+const (
+	HS256 = "HS256"
+	EdDSA = "EdDSA"
+)
+
+const opaqueTokenBytes = 32
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims are carried by access token.
+// Subject holds on ID of the authenticated user.
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"perms,omitempty"`
+}
+
+// Manager issues and verifies signed access tokens.
+// Once issuer and audience are set, tokens carry them
+// and only tokens carrying the same ones are accepted.
+type Manager struct {
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+	ttl       time.Duration
+	issuer    string
+	audience  string
+}
+
+// NewHS256 returns Manager signing tokens with HMAC-SHA256 shared secret.
+func NewHS256(secret []byte, ttl time.Duration) Manager {
+	return Manager{
+		method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+		ttl:       ttl,
+	}
+}
+
+// NewEdDSA returns Manager signing tokens with Ed25519 private key.
+func NewEdDSA(key ed25519.PrivateKey, ttl time.Duration) Manager {
+	return Manager{
+		method:    jwt.SigningMethodEdDSA,
+		signKey:   key,
+		verifyKey: key.Public(),
+		ttl:       ttl,
+	}
+}
+
+// WithIssuer returns copy of Manager issuing tokens by issuer for audience.
+func (m Manager) WithIssuer(issuer, audience string) Manager {
+	m.issuer, m.audience = issuer, audience
+
+	return m
+}
+
+// LoadEdDSAKey reads PEM encoded PKCS #8 Ed25519 private key from file.
+func LoadEdDSAKey(path string) (ed25519.PrivateKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading private key file: %w", err)
+	}
+
+	key, err := jwt.ParseEdPrivateKeyFromPEM(b)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing private key: %w", err)
+	}
+
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not ed25519 key")
+	}
+
+	return edKey, nil
+}
+
+// Issue signs claims setting issued-at and expiry,
+// and returns token along with its expiry time.
+func (m Manager) Issue(claims Claims) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.ttl)
+
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+
+	if m.issuer != "" {
+		claims.Issuer = m.issuer
+	}
+
+	if m.audience != "" {
+		claims.Audience = jwt.ClaimStrings{m.audience}
+	}
+
+	signed, err := jwt.NewWithClaims(m.method, claims).SignedString(m.signKey)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error signing token: %w", err)
+	}
+
+	return signed, expiresAt, nil
+}
+
+// Parse verifies token signature, expiry, issuer and audience and returns its claims.
+func (m Manager) Parse(token string) (*Claims, error) {
+	var claims Claims
+
+	keyFunc := func(*jwt.Token) (any, error) { return m.verifyKey, nil }
+
+	if _, err := jwt.ParseWithClaims(token, &claims, keyFunc, jwt.WithValidMethods([]string{m.method.Alg()})); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if claims.Subject == "" || claims.ExpiresAt == nil {
+		return nil, fmt.Errorf("%w: subject and expiry are required", ErrInvalidToken)
+	}
+
+	if m.issuer != "" && !claims.VerifyIssuer(m.issuer, true) {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	}
+
+	if m.audience != "" && !claims.VerifyAudience(m.audience, true) {
+		return nil, fmt.Errorf("%w: unexpected audience %v", ErrInvalidToken, claims.Audience)
+	}
+
+	return &claims, nil
+}
+
+// NewOpaque returns random URL-safe token
+// suitable for refresh or one-time tokens.
+func NewOpaque() (string, error) {
+	b := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,145 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	issuer   = "octagon"
+	audience = "octagon-api"
+)
+
+func TestManager_Parse(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	hs256 := NewHS256(secret, time.Minute).WithIssuer(issuer, audience)
+	eddsa := NewEdDSA(private, time.Minute).WithIssuer(issuer, audience)
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "4fddf9a4-fbd1-4083-98aa-e4d0e584e7bb"},
+		Roles:            []string{"member"},
+	}
+
+	tests := map[string]struct {
+		issuer Manager
+		parser Manager
+		claims Claims
+		expErr bool
+	}{
+		"hs256": {
+			issuer: hs256,
+			parser: hs256,
+			claims: claims,
+		},
+		"eddsa": {
+			issuer: eddsa,
+			parser: eddsa,
+			claims: claims,
+		},
+		"hs256_signed_with_eddsa_public_key": {
+			issuer: NewHS256(public, time.Minute).WithIssuer(issuer, audience),
+			parser: eddsa,
+			claims: claims,
+			expErr: true,
+		},
+		"eddsa_token_for_hs256": {
+			issuer: eddsa,
+			parser: hs256,
+			claims: claims,
+			expErr: true,
+		},
+		"wrong_secret": {
+			issuer: NewHS256([]byte("abcdef0123456789abcdef0123456789"), time.Minute).WithIssuer(issuer, audience),
+			parser: hs256,
+			claims: claims,
+			expErr: true,
+		},
+		"expired": {
+			issuer: NewHS256(secret, -time.Minute).WithIssuer(issuer, audience),
+			parser: hs256,
+			claims: claims,
+			expErr: true,
+		},
+		"wrong_issuer": {
+			issuer: hs256.WithIssuer("other", audience),
+			parser: hs256,
+			claims: claims,
+			expErr: true,
+		},
+		"wrong_audience": {
+			issuer: hs256.WithIssuer(issuer, "other"),
+			parser: hs256,
+			claims: claims,
+			expErr: true,
+		},
+		"no_issuer_and_audience": {
+			issuer: NewHS256(secret, time.Minute),
+			parser: hs256,
+			claims: claims,
+			expErr: true,
+		},
+		"no_subject": {
+			issuer: hs256,
+			parser: hs256,
+			expErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			signed, expiresAt, err := tt.issuer.Issue(tt.claims)
+			require.NoError(t, err)
+
+			parsed, err := tt.parser.Parse(signed)
+			if tt.expErr {
+				require.ErrorIs(t, err, ErrInvalidToken)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.claims.Subject, parsed.Subject)
+			require.Equal(t, tt.claims.Roles, parsed.Roles)
+			require.Equal(t, issuer, parsed.Issuer)
+			require.Equal(t, jwt.ClaimStrings{audience}, parsed.Audience)
+			require.WithinDuration(t, expiresAt, parsed.ExpiresAt.Time, time.Second)
+		})
+	}
+
+	t.Run("alg_none", func(t *testing.T) {
+		claims := claims
+		claims.Issuer, claims.Audience = issuer, jwt.ClaimStrings{audience}
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Minute))
+
+		unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+		require.NoError(t, err)
+
+		_, err = hs256.Parse(unsigned)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func TestNewOpaque(t *testing.T) {
+	seen := make(map[string]bool)
+
+	for i := 0; i < 100; i++ {
+		opaque, err := NewOpaque()
+		require.NoError(t, err)
+
+		b, err := base64.RawURLEncoding.DecodeString(opaque)
+		require.NoError(t, err, "token has to be URL-safe")
+		require.Len(t, b, opaqueTokenBytes)
+
+		require.False(t, seen[opaque], "tokens have to be random")
+		seen[opaque] = true
+	}
+}